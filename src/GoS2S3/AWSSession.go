@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/ec2rolecreds"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"net/http"
+	"os"
+	"time"
+)
+
+// values accepted by AWSConfiguration.Credentials_source
+const (
+	// static keys if set, otherwise the named profile if set, otherwise the SDK default chain
+	// (environment, shared files, web identity, container and instance role)
+	credentialsSourceAuto         = ""
+	credentialsSourceStatic       = "static"
+	credentialsSourceProfile      = "profile"
+	credentialsSourceWebIdentity  = "web_identity"
+	credentialsSourceInstanceRole = "instance_role"
+)
+
+// session name used when assuming a role if none is configured
+const defaultRoleSessionName = "GoS2S3"
+
+// assumed role credentials are refreshed this long before they expire, so every part
+// of a multipart upload is signed with credentials that are still valid
+const roleExpiryWindow = 5 * time.Minute
+
+// newAmazonSession builds the S3 session from the credentials described in the AWS section
+// without reading or changing the credentials in the process environment, so the ones
+// injected by the platform (ie. IAM roles for service accounts) are left untouched.
+// When Role_arn is set the chosen credentials are only used to assume that role.
+func newAmazonSession(amazonConfiguration AWSConfiguration, httpClient *http.Client) (*session.Session, error) {
+	options := session.Options{
+		Config:            aws.Config{Region: aws.String(amazonConfiguration.Region), HTTPClient: httpClient},
+		SharedConfigState: session.SharedConfigEnable,
+	}
+
+	credentialsSource := amazonConfiguration.credentialsSource()
+	switch credentialsSource {
+	case credentialsSourceStatic:
+		options.Config.Credentials = credentials.NewStaticCredentials(amazonConfiguration.Access_key_ID, amazonConfiguration.Secret_access_key, amazonConfiguration.Session_token)
+	case credentialsSourceProfile:
+		options.Profile = amazonConfiguration.Profile
+	}
+
+	amazonSession, sessionError := session.NewSessionWithOptions(options)
+	if sessionError != nil {
+		return nil, fmt.Errorf("creating AWS session: %w", sessionError)
+	}
+
+	switch credentialsSource {
+	case credentialsSourceWebIdentity:
+		roleArn, tokenFile := amazonConfiguration.webIdentity()
+		amazonSession.Config.Credentials = stscreds.NewWebIdentityCredentials(amazonSession, roleArn, amazonConfiguration.roleSessionName(), tokenFile)
+	case credentialsSourceInstanceRole:
+		amazonSession.Config.Credentials = ec2rolecreds.NewCredentials(amazonSession)
+	}
+
+	if amazonConfiguration.Role_arn != "" {
+		// the STS client is created here, signing with the credentials chosen above
+		amazonSession.Config.Credentials = stscreds.NewCredentials(amazonSession, amazonConfiguration.Role_arn, func(provider *stscreds.AssumeRoleProvider) {
+			provider.RoleSessionName = amazonConfiguration.roleSessionName()
+			provider.ExpiryWindow = roleExpiryWindow
+			if amazonConfiguration.Role_duration > 0 {
+				provider.Duration = time.Duration(amazonConfiguration.Role_duration)
+			}
+			if amazonConfiguration.External_id != "" {
+				provider.ExternalID = aws.String(amazonConfiguration.External_id)
+			}
+		})
+	}
+
+	return amazonSession, nil
+}
+
+func (amazonConfiguration AWSConfiguration) credentialsSource() string {
+	if amazonConfiguration.Credentials_source != credentialsSourceAuto {
+		return amazonConfiguration.Credentials_source
+	}
+	switch {
+	case amazonConfiguration.Access_key_ID != "" || amazonConfiguration.Secret_access_key != "":
+		return credentialsSourceStatic
+	case amazonConfiguration.Profile != "":
+		return credentialsSourceProfile
+	case amazonConfiguration.Web_identity_role_arn != "" || amazonConfiguration.Web_identity_token_file != "":
+		// the missing half falls back to AWS_ROLE_ARN or AWS_WEB_IDENTITY_TOKEN_FILE, see webIdentity
+		return credentialsSourceWebIdentity
+	}
+	return credentialsSourceAuto
+}
+
+// webIdentity returns the role and the token file used for the web identity federation,
+// falling back to the variables set by EKS for IAM roles for service accounts
+func (amazonConfiguration AWSConfiguration) webIdentity() (roleArn string, tokenFile string) {
+	roleArn = amazonConfiguration.Web_identity_role_arn
+	if roleArn == "" {
+		roleArn = os.Getenv("AWS_ROLE_ARN")
+	}
+	tokenFile = amazonConfiguration.Web_identity_token_file
+	if tokenFile == "" {
+		tokenFile = os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE")
+	}
+	return
+}
+
+func (amazonConfiguration AWSConfiguration) roleSessionName() string {
+	if amazonConfiguration.Role_session_name != "" {
+		return amazonConfiguration.Role_session_name
+	}
+	return defaultRoleSessionName
+}
+
+// credentialsDescription tells where the credentials come from, for logging purposes
+func (amazonConfiguration AWSConfiguration) credentialsDescription() string {
+	description := amazonConfiguration.credentialsSource()
+	if description == credentialsSourceAuto {
+		description = "default chain"
+	}
+	if amazonConfiguration.Role_arn != "" {
+		description += ", assuming " + amazonConfiguration.Role_arn
+	}
+	return description
+}
@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestCredentialsSource(t *testing.T) {
+	tests := []struct {
+		name          string
+		configuration AWSConfiguration
+		source        string
+	}{
+		{"nothing configured", AWSConfiguration{}, credentialsSourceAuto},
+		{"static keys", AWSConfiguration{Access_key_ID: "AKIA", Secret_access_key: "secret"}, credentialsSourceStatic},
+		{"profile", AWSConfiguration{Profile: "backup"}, credentialsSourceProfile},
+		{"web identity role only", AWSConfiguration{Web_identity_role_arn: "arn:aws:iam::123456789012:role/backup"}, credentialsSourceWebIdentity},
+		{"web identity token only", AWSConfiguration{Web_identity_token_file: "/var/run/secrets/token"}, credentialsSourceWebIdentity},
+		{"explicit source", AWSConfiguration{Credentials_source: credentialsSourceInstanceRole, Web_identity_role_arn: "arn:aws:iam::123456789012:role/backup"}, credentialsSourceInstanceRole},
+	}
+	for _, test := range tests {
+		if source := test.configuration.credentialsSource(); source != test.source {
+			t.Errorf("%s: credentialsSource() = %q, want %q", test.name, source, test.source)
+		}
+	}
+}
@@ -4,92 +4,220 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
 	"time"
 	// "encoding/json"
-	"GoS2S3/SalesforceWSDL"
+	"GoS2S3/httpUtil"
 	"GoS2S3/salesforceUtil"
-	"github.com/aws/aws-sdk-go/aws/session"
 )
 
 var debug bool
+var trace bool
 var timestampEpoch time.Time
 var todayEpoch int64
 
-func main() {
+// deadline of the logout closing a session, even after the run was interrupted
+const logoutTimeout = 30 * time.Second
 
-	arguments := os.Args
-  
-	for index, element := range arguments {
-		if element == "-help"{
-			// Put here some help explanation
-			fmt.Println("This is a help page!")
-			os.Exit(3)
-		}
-	}
+// subcommands accepted before or after the flags, running without one performs the backup
+const validateConfigCommand = "validate-config"
+const exportDataCommand = "export-data"
 
-	timestampEpoch = time.Now()
-	todayEpoch = timestampEpoch.Unix() - (timestampEpoch.Unix() % 86400)
+func main() {
 
-	var SF_Soap SalesforceWSDL.Soap
-	var SF_BasicAuth SalesforceWSDL.BasicAuth
+	var configFileName string
 
-	var activeSalesforceConnection salesforceUtil.SF_connection
+	// Command line parsing
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [%s|%s] [flags]\n\n", os.Args[0], validateConfigCommand, exportDataCommand)
+		fmt.Fprintln(flag.CommandLine.Output(), "Downloads the Salesforce weekly data export and uploads it to an S3 bucket.")
+		fmt.Fprintf(flag.CommandLine.Output(), "With %s only checks the configuration and reports every problem found.\n", validateConfigCommand)
+		fmt.Fprintf(flag.CommandLine.Output(), "With %s queries the objects of the Export section and uploads one CSV file per object.\n", exportDataCommand)
+		fmt.Fprintln(flag.CommandLine.Output(), "Configuration precedence: flags > environment variables > configuration file.")
+		fmt.Fprintln(flag.CommandLine.Output(), "")
+		flag.PrintDefaults()
+	}
+	flag.BoolVar(&debug, "debug", false, "Activate debug mode")
+	flag.BoolVar(&trace, "trace", false, "Log the HTTP requests and responses, with passwords, session ids and tokens redacted")
+	flag.StringVar(&configFileName, "config", "", "Path of the configuration file (default $"+configFileEnvironmentName+" or "+defaultConfigFileName+")")
+	configurationFlags := registerConfigurationFlags(flag.CommandLine)
 
-	// --------------------- INITIALIZATION ---------------------
+	command, commandError := parseCommandLine(flag.CommandLine, os.Args[1:])
+	if commandError != nil {
+		fmt.Fprintf(flag.CommandLine.Output(), "%v\n\n", commandError)
+		flag.Usage()
+		os.Exit(2)
+	}
 
-	activeSalesforceConnection.ConnectionCookies = make(map[string]interface{}, 0)
+	// validate-config runs where the secrets may not be available (ie. in CI), it only checks their references
+	configuration, configurationError := loadConfiguration(configFileName, flag.CommandLine, configurationFlags, command != validateConfigCommand)
+	if configurationError != nil {
+		log.Printf("Error loading the configuration: %v", configurationError)
+		os.Exit(1)
+	}
 
-	// Command line parsing
-	flag.BoolVar(&debug, "debug", false, "Activate debug mode")
-	flag.Parse()
+	validationError := configuration.Validate()
+	if command == validateConfigCommand {
+		if validationError != nil {
+			fmt.Println("Configuration is NOT valid:")
+			fmt.Println(validationError)
+			os.Exit(1)
+		}
+		fmt.Println("Configuration is valid")
+		return
+	}
+	if validationError != nil {
+		log.Printf("Invalid configuration:\n%v", validationError)
+		os.Exit(1)
+	}
 
 	if debug {
 		log.Println("")
 		log.Println("DEBUG MODE ON!")
 		log.Println("")
-		activeSalesforceConnection.Debug = debug
 	} else {
 		log.Println("we are in NORMAL mode")
 	}
+	if trace {
+		log.Println("Wire trace ON, secrets are redacted")
+		httpUtil.EnableTrace(true)
+	}
 
+	// SIGINT and SIGTERM (ie. a pod shutdown) cancel the transfers in progress, their partial files are removed
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	for index, element := range arguments {
-		if element == "-config" {
-			var configuration Configuration
-		
-			configuration.LoadConfigFrom("application-config.json")
-		}
+	backup := backupOrg
+	if command == exportDataCommand {
+		backup = exportOrgData
 	}
+	if !runBackups(ctx, configuration, backup) {
+		os.Exit(1)
+	}
+}
+
+// connectOrg logs in to the org, or resumes its cached session, and checks the API version.
+// The returned logout closes the session and must be called once the org is done.
+func connectOrg(ctx context.Context, org OrgConfiguration, run backupRun) (connection *salesforceUtil.SF_connection, logout func(), connectionError error) {
+	var activeSalesforceConnection salesforceUtil.SF_connection
+
+	// --------------------- INITIALIZATION ---------------------
+
+	activeSalesforceConnection.ConnectionCookies = make(map[string]interface{}, 0)
+	activeSalesforceConnection.Debug = debug
+	activeSalesforceConnection.HTTPClient = run.httpClient
+	activeSalesforceConnection.RetryPolicy = run.retryPolicy
 
 	// refactor methods to use pointer to struct
-	loadSalesforceConfigurationFromFile(&configuration.Salesforce, &activeSalesforceConnection)
-	loadAWSConfigurationFromFile(&configuration.Amazon)
+	loadSalesforceConfigurationFromFile(&org.Salesforce, &activeSalesforceConnection)
 	// --------------------- END INITIALIZATION ---------------------
 
-	activeSalesforceConnection.GetAuthenticationToken()
+	authenticationContext, cancelAuthentication := run.timeouts.Authentication.withTimeout(ctx)
+	defer cancelAuthentication()
+
+	// a session cached by a previous run saves both the OAuth and the SOAP logins
+	resumedSession := activeSalesforceConnection.ResumeCachedSession(authenticationContext)
+	if !resumedSession {
+		log.Printf("[%s] Authenticating as %s", org.Name, org.Salesforce.Username)
+		if _, authenticationError := activeSalesforceConnection.GetAuthenticationToken(authenticationContext); authenticationError != nil {
+			return nil, nil, authenticationError
+		}
+	}
+
+	if versionError := activeSalesforceConnection.CheckAPIVersion(authenticationContext); versionError != nil {
+		return nil, nil, versionError
+	}
+	log.Printf("[%s] Using API version %s", org.Name, activeSalesforceConnection.APIVersion())
+
+	if !resumedSession {
+		if _, _, soapError := activeSalesforceConnection.AuthenticateThroughSOAP(authenticationContext); soapError != nil {
+			return nil, nil, soapError
+		}
+	}
 
-	SF_Soap, SF_BasicAuth = activeSalesforceConnection.AuthenticateThroughSOAP()
+	logout = func() {
+		// the run context may already be cancelled, the logout gets its own short deadline
+		logoutContext, cancelLogout := context.WithTimeout(context.Background(), logoutTimeout)
+		defer cancelLogout()
+		if logoutError := activeSalesforceConnection.Logout(logoutContext); logoutError != nil {
+			log.Printf("[%s] Error logging out from Salesforce: %v", org.Name, logoutError)
+		}
+	}
+	return &activeSalesforceConnection, logout, nil
+}
+
+// parseCommandLine parses the flags and returns the subcommand, which can come before, between or
+// after them. Any other argument is refused: a misplaced subcommand must never run a backup instead.
+func parseCommandLine(flagSet *flag.FlagSet, arguments []string) (string, error) {
+	command := ""
+	for {
+		if parseError := flagSet.Parse(arguments); parseError != nil {
+			return "", parseError
+		}
+		if flagSet.NArg() == 0 {
+			break
+		}
+		if command != "" {
+			return "", fmt.Errorf("unexpected arguments %q", flagSet.Args())
+		}
+		command = flagSet.Arg(0)
+		arguments = flagSet.Args()[1:]
+	}
+
+	if command != "" && command != validateConfigCommand && command != exportDataCommand {
+		return "", fmt.Errorf("unknown command %q", command)
+	}
+	return command, nil
+}
+
+// backupOrg downloads the data export of a single org and uploads every file to its S3 destination
+// Every network call is aborted when ctx is cancelled, each phase is also bounded by its own timeout.
+func backupOrg(ctx context.Context, org OrgConfiguration, amazonConfiguration AWSConfiguration, run backupRun) (result orgBackupResult) {
+	result.Name = org.Name
+
+	activeSalesforceConnection, logout, connectionError := connectOrg(ctx, org, run)
+	if connectionError != nil {
+		result.Err = connectionError
+		return
+	}
+	defer logout()
 
-	downloadPage := activeSalesforceConnection.RequestPageOAuth(activeSalesforceConnection.AuthenticationToken.Instance_url + "/ui/setup/export/DataExportPage/d?setupid=DataManagementExport&retURL=%2Fui%2Fsetup%2FSetup%3Fsetupid%3DDataManagementq")
+	exportPageURL, urlError := activeSalesforceConnection.InstanceURL("/ui/setup/export/DataExportPage/d?setupid=DataManagementExport&retURL=%2Fui%2Fsetup%2FSetup%3Fsetupid%3DDataManagementq")
+	if urlError != nil {
+		result.Err = fmt.Errorf("building the data export page URL: %w", urlError)
+		return
+	}
+	var downloadPage string
+	pageContext, cancelPage := run.timeouts.Export_page.withTimeout(ctx)
+	pageError := activeSalesforceConnection.WithSession(pageContext, func() (requestError error) {
+		downloadPage, requestError = activeSalesforceConnection.RequestPageOAuth(pageContext, exportPageURL)
+		return
+	})
+	cancelPage()
+	if pageError != nil {
+		result.Err = pageError
+		return
+	}
 
-	log.Println("Extracting download links... ")
+	log.Printf("[%s] Extracting download links... ", org.Name)
 	var downloadLinks = extractDownloadLinks(downloadPage)
 	downloadLinks = prependBasicUrl(downloadLinks, activeSalesforceConnection.AuthenticationToken.Instance_url)
 	if activeSalesforceConnection.Debug {
-		log.Println("URls found in page:")
+		log.Printf("[%s] URls found in page:", org.Name)
 		for _, element := range downloadLinks {
 			log.Printf("\t- %s\n", element)
 		}
 	} else {
-		log.Printf("%d links found in the page", len(downloadLinks))
+		log.Printf("[%s] %d links found in the page", org.Name, len(downloadLinks))
 	}
 
-	amazonSession := session.Must(session.NewSession())
-	log.Println("Amazon session created")
-
 	if debug {
 		downloadLinks = make([]string, 0)
 		downloadLinks = append(downloadLinks, "https://raw.githubusercontent.com/nikotrone/GoS2S3/master/README.md?fileName=test.foo")
@@ -97,30 +225,34 @@ func main() {
 
 	if len(downloadLinks) == 0 {
 		log.Println("")
-		log.Println("Nothing to do")
+		log.Printf("[%s] Nothing to do", org.Name)
 		log.Println("")
 		return
 	}
 
-	creationError := os.Mkdir("tmp", 0777)
-	if (creationError != nil) && (!os.IsExist(creationError)) {
-		log.Printf("Error creating destination folder: %v", creationError)
-		panic(1)
+	destinationFolder := filepath.Join("tmp", org.Name)
+	creationError := os.MkdirAll(destinationFolder, 0777)
+	if creationError != nil {
+		result.Err = fmt.Errorf("creating destination folder: %w", creationError)
+		return
 	}
 
 	for _, value := range downloadLinks {
-		fileName, transferError := transferFile(value, activeSalesforceConnection.ConnectionCookies, amazonSession, configuration)
+		if ctx.Err() != nil {
+			result.Err = fmt.Errorf("backup interrupted: %w", ctx.Err())
+			result.FilesFailed += len(downloadLinks) - result.FilesTransferred - result.FilesFailed
+			break
+		}
+		fileName, transferError := transferFile(ctx, value, activeSalesforceConnection, amazonConfiguration, destinationFolder, run)
 		if transferError != nil {
-			log.Printf("Error while transfering file %s: %v", fileName, transferError)
+			log.Printf("[%s] Error while transfering file %s: %v", org.Name, fileName, transferError)
+			result.FilesFailed++
+		} else {
+			result.FilesTransferred++
 		}
 	}
 
-	// to avoid the imported and not used error
-	if false {
-		log.Println(SF_Soap)
-		log.Println(SF_BasicAuth)
-	}
-
+	return
 }
 
 func prependBasicUrl(links []string, basicUrl string) []string {
@@ -134,9 +266,17 @@ func prependBasicUrl(links []string, basicUrl string) []string {
 	return links
 }
 
-func transferFile(downloadLink string, salesforceConnectionCookies map[string]interface{}, amazonSession *session.Session, applicationConfiguration Configuration) (fileName string, transferError error) {
+func transferFile(ctx context.Context, downloadLink string, salesforceConnection *salesforceUtil.SF_connection, amazonConfiguration AWSConfiguration, destinationFolder string, run backupRun) (fileName string, transferError error) {
 	log.Printf("Downloading file: %s", downloadLink)
-	fileName, downloadError := downloadFileFromUrl(downloadLink, salesforceConnectionCookies)
+	downloadContext, cancelDownload := run.timeouts.Download.withTimeout(ctx)
+	defer cancelDownload()
+	// the timeout covers every attempt; the cookies are read on each of them, a re-login replaces the sid
+	downloadError := run.retryPolicy.Do(downloadContext, "download of "+downloadLink, func() error {
+		return salesforceConnection.WithSession(downloadContext, func() (attemptError error) {
+			fileName, attemptError = downloadFileFromUrl(downloadContext, salesforceConnection.HTTPClient, downloadLink, salesforceConnection.ConnectionCookies, destinationFolder)
+			return
+		})
+	})
 	if downloadError != nil {
 		log.Println("Error downloading the file from target location")
 		return fileName, downloadError
@@ -144,14 +284,36 @@ func transferFile(downloadLink string, salesforceConnectionCookies map[string]in
 		log.Println("Download Successful!!")
 	}
 
+	return fileName, shipFile(ctx, fileName, amazonConfiguration, destinationFolder, run)
+}
+
+// shipFile uploads a file of destinationFolder to the S3 destination, the file is removed once uploaded
+func shipFile(ctx context.Context, fileName string, amazonConfiguration AWSConfiguration, destinationFolder string, run backupRun) error {
 	log.Println("Uploading file to S3 bucket...")
-	_, uploadError := uploadFileToS3(amazonSession, applicationConfiguration.Amazon, fileName)
+	uploadContext, cancelUpload := run.timeouts.Upload.withTimeout(ctx)
+	defer cancelUpload()
+	uploadError := run.retryPolicy.Do(uploadContext, "upload of "+fileName, func() (attemptError error) {
+		_, attemptError = uploadFileToS3(uploadContext, run.amazonSession, amazonConfiguration, destinationFolder, fileName)
+		return
+	})
 	if uploadError != nil {
 		log.Println("Error downloading the file from target location")
-		return fileName, uploadError
+		if ctx.Err() != nil {
+			// interrupted: nothing is left behind in the temporary folder
+			os.Remove(filepath.Join(destinationFolder, fileName))
+		}
+		return uploadError
 	} else {
 		log.Println("Upload Successful!!")
 	}
 
-	return fileName, nil
+	return nil
+}
+
+// withTimeout derives the context of a phase, a zero timeout leaves the phase bounded only by ctx
+func (timeout Duration) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Duration(timeout))
 }
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"testing"
+)
+
+func TestParseCommandLine(t *testing.T) {
+	tests := []struct {
+		arguments []string
+		command   string
+		config    string
+		valid     bool
+	}{
+		{arguments: nil, valid: true},
+		{arguments: []string{"-config", "app.json"}, config: "app.json", valid: true},
+		{arguments: []string{"validate-config", "-config", "app.json"}, command: validateConfigCommand, config: "app.json", valid: true},
+		{arguments: []string{"-config", "app.json", "validate-config"}, command: validateConfigCommand, config: "app.json", valid: true},
+		{arguments: []string{"-config", "app.json", "export-data", "-debug"}, command: exportDataCommand, config: "app.json", valid: true},
+		{arguments: []string{"-config", "app.json", "backup"}, valid: false},
+		{arguments: []string{"validate-config", "export-data"}, valid: false},
+		{arguments: []string{"validate-config", "-config", "app.json", "extra"}, valid: false},
+		{arguments: []string{"-unknown"}, valid: false},
+	}
+	for _, test := range tests {
+		flagSet := flag.NewFlagSet("test", flag.ContinueOnError)
+		flagSet.SetOutput(ioutil.Discard)
+		configFileName := flagSet.String("config", "", "")
+		flagSet.Bool("debug", false, "")
+
+		command, parseError := parseCommandLine(flagSet, test.arguments)
+		if (parseError == nil) != test.valid {
+			t.Errorf("%q: error %v, want valid=%v", test.arguments, parseError, test.valid)
+			continue
+		}
+		if parseError == nil && (command != test.command || *configFileName != test.config) {
+			t.Errorf("%q: command %q and config %q, want %q and %q", test.arguments, command, *configFileName, test.command, test.config)
+		}
+	}
+}
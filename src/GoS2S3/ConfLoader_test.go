@@ -0,0 +1,111 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+const precedenceConfigFile = `{
+	"Salesforce": {"Username": "file-user", "ClientId": "file-client", "Compression": false},
+	"HTTP": {"Max_idle_conns_per_host": 5}
+}`
+
+// loadTestConfiguration loads a configuration file written in a temporary folder with the given command line
+func loadTestConfiguration(t *testing.T, arguments ...string) Configuration {
+	t.Helper()
+	configFileName := filepath.Join(t.TempDir(), "application-config.json")
+	if writeError := ioutil.WriteFile(configFileName, []byte(precedenceConfigFile), 0600); writeError != nil {
+		t.Fatal(writeError)
+	}
+
+	flagSet := flag.NewFlagSet("test", flag.ContinueOnError)
+	overrides := registerConfigurationFlags(flagSet)
+	if parseError := flagSet.Parse(arguments); parseError != nil {
+		t.Fatalf("parsing %v: %v", arguments, parseError)
+	}
+	configuration, loadError := loadConfiguration(configFileName, flagSet, overrides, true)
+	if loadError != nil {
+		t.Fatalf("loading the configuration: %v", loadError)
+	}
+	return configuration
+}
+
+func TestConfigurationPrecedence(t *testing.T) {
+	tests := []struct {
+		name        string
+		environment map[string]string
+		arguments   []string
+		username    string
+		clientId    string
+	}{
+		{name: "file only", username: "file-user", clientId: "file-client"},
+		{
+			name:        "environment over file",
+			environment: map[string]string{"GOS2S3_SALESFORCE_USERNAME": "env-user"},
+			username:    "env-user",
+			clientId:    "file-client",
+		},
+		{
+			name:        "flag over environment",
+			environment: map[string]string{"GOS2S3_SALESFORCE_USERNAME": "env-user", "GOS2S3_SALESFORCE_CLIENTID": "env-client"},
+			arguments:   []string{"-salesforce.username", "flag-user"},
+			username:    "flag-user",
+			clientId:    "env-client",
+		},
+		{
+			name:      "flag over file",
+			arguments: []string{"-salesforce.clientid=flag-client"},
+			username:  "file-user",
+			clientId:  "flag-client",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for name, value := range test.environment {
+				t.Setenv(name, value)
+			}
+			configuration := loadTestConfiguration(t, test.arguments...)
+			if configuration.Salesforce.Username != test.username {
+				t.Errorf("Username = %q, want %q", configuration.Salesforce.Username, test.username)
+			}
+			if configuration.Salesforce.ClientId != test.clientId {
+				t.Errorf("ClientId = %q, want %q", configuration.Salesforce.ClientId, test.clientId)
+			}
+		})
+	}
+}
+
+func TestConfigurationFlagTypes(t *testing.T) {
+	t.Setenv("GOS2S3_HTTP_MAX_IDLE_CONNS_PER_HOST", "7")
+
+	configuration := loadTestConfiguration(t, "-salesforce.compression", "-http.disable_keep_alives=false", "-timeouts.download", "90")
+	if !configuration.Salesforce.Compression {
+		t.Error("a bool flag given without a value must set the field")
+	}
+	if configuration.HTTP.Disable_keep_alives {
+		t.Error("-http.disable_keep_alives=false must leave the field false")
+	}
+	if configuration.HTTP.Max_idle_conns_per_host != 7 {
+		t.Errorf("Max_idle_conns_per_host = %d, want the environment value 7", configuration.HTTP.Max_idle_conns_per_host)
+	}
+	if configuration.Timeouts.Download != Duration(90e9) {
+		t.Errorf("Download = %v, want 90s", configuration.Timeouts.Download)
+	}
+}
+
+func TestConfigurationFlagInvalidValue(t *testing.T) {
+	configFileName := filepath.Join(t.TempDir(), "application-config.json")
+	if writeError := ioutil.WriteFile(configFileName, []byte(precedenceConfigFile), 0600); writeError != nil {
+		t.Fatal(writeError)
+	}
+	flagSet := flag.NewFlagSet("test", flag.ContinueOnError)
+	overrides := registerConfigurationFlags(flagSet)
+	if parseError := flagSet.Parse([]string{"-salesforce.compression=maybe"}); parseError != nil {
+		t.Fatal(parseError)
+	}
+	if _, loadError := loadConfiguration(configFileName, flagSet, overrides, true); loadError == nil {
+		t.Error("an invalid boolean must be reported")
+	}
+}
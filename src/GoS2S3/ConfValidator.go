@@ -0,0 +1,377 @@
+package main
+
+import (
+	"GoS2S3/salesforceUtil"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var awsRegionPattern = regexp.MustCompile(`^[a-z]{2}(-[a-z]+)+-[0-9]+$`)
+var bucketNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+var apiVersionPattern = regexp.MustCompile(`^v?[0-9]+\.0$`)
+var roleArnPattern = regexp.MustCompile(`^arn:aws[a-z-]*:iam::[0-9]{12}:role/.+$`)
+var faultCodePattern = regexp.MustCompile(`^[A-Z_]+$`)
+var roleSessionNamePattern = regexp.MustCompile(`^[\w+=,.@-]{2,64}$`)
+var objectNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// org names are used as folder names for the downloaded files
+var orgNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// ConfigurationError describes a single problem found on a configuration field
+type ConfigurationError struct {
+	Field   string
+	Problem string
+}
+
+func (configurationError ConfigurationError) Error() string {
+	return configurationError.Field + ": " + configurationError.Problem
+}
+
+// ConfigurationErrors collects every problem found by Validate so they can be reported at once
+type ConfigurationErrors []ConfigurationError
+
+func (configurationErrors ConfigurationErrors) Error() string {
+	problems := make([]string, 0, len(configurationErrors))
+	for _, configurationError := range configurationErrors {
+		problems = append(problems, "\t- "+configurationError.Error())
+	}
+	return strings.Join(problems, "\n")
+}
+
+func (configurationErrors *ConfigurationErrors) add(field string, problem string) {
+	*configurationErrors = append(*configurationErrors, ConfigurationError{Field: field, Problem: problem})
+}
+
+func (configurationErrors *ConfigurationErrors) require(field string, value string) bool {
+	if strings.TrimSpace(value) == "" {
+		configurationErrors.add(field, "is required")
+		return false
+	}
+	return true
+}
+
+// Validate checks the whole configuration and returns a ConfigurationErrors listing
+// every problem found, or nil when the configuration can be used
+func (connectionsConf Configuration) Validate() error {
+	var problems ConfigurationErrors
+
+	connectionsConf.Amazon.validate("AWS", &problems)
+
+	if len(connectionsConf.Orgs) == 0 {
+		connectionsConf.Salesforce.validate("Salesforce", &problems)
+		problems.require("AWS.s3_destination_bucket", connectionsConf.Amazon.S3_destination_bucket)
+	}
+
+	orgNames := make(map[string]bool)
+	for index, org := range connectionsConf.Orgs {
+		section := fmt.Sprintf("Orgs[%d]", index)
+		if problems.require(section+".Name", org.Name) {
+			if !orgNamePattern.MatchString(org.Name) || org.Name == "." || org.Name == ".." {
+				problems.add(section+".Name", "can only contain letters, numbers, dots, hyphens and underscores")
+			}
+			if orgNames[org.Name] {
+				problems.add(section+".Name", "\""+org.Name+"\" is used by more than one org")
+			}
+			orgNames[org.Name] = true
+		}
+
+		org.Salesforce.validate(section+".Salesforce", &problems)
+
+		if org.S3_destination_bucket != "" {
+			validateBucketName(section+".s3_destination_bucket", org.S3_destination_bucket, &problems)
+		} else if connectionsConf.Amazon.S3_destination_bucket == "" {
+			problems.add(section+".s3_destination_bucket", "is required when AWS.s3_destination_bucket is not set")
+		}
+		if strings.HasPrefix(org.S3_destination_path, "/") {
+			problems.add(section+".s3_destination_path", "must not start with \"/\"")
+		}
+	}
+
+	if connectionsConf.ParallelOrgs < 0 {
+		problems.add("ParallelOrgs", "must not be negative")
+	}
+	connectionsConf.Timeouts.validate("Timeouts", &problems)
+	connectionsConf.HTTP.validate("HTTP", &problems)
+	connectionsConf.Retry.validate("Retry", &problems)
+	connectionsConf.Export.validate("Export", &problems)
+	connectionsConf.validateSecretReferences(&problems)
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return problems
+}
+
+func (salesforceConf SalesforceConfiguration) validate(section string, problems *ConfigurationErrors) {
+	switch salesforceConf.Environment {
+	case "", salesforceUtil.EnvironmentProduction, salesforceUtil.EnvironmentSandbox:
+	default:
+		problems.add(section+".Environment", "must be \"production\" or \"sandbox\"")
+	}
+	if salesforceConf.ApiVersion != "" && !apiVersionPattern.MatchString(salesforceConf.ApiVersion) {
+		problems.add(section+".ApiVersion", "must be a version number like \""+salesforceUtil.DefaultAPIVersion+"\"")
+	}
+	if salesforceConf.SessionMaxAge < 0 {
+		problems.add(section+".SessionMaxAge", "must not be negative")
+	}
+	if salesforceConf.TokenCacheFolder != "" && salesforceConf.TokenCacheKey == "" {
+		problems.add(section+".TokenCacheKey", "is required by TokenCacheFolder")
+	} else if salesforceConf.TokenCacheKey != "" && !isSecretReference(salesforceConf.TokenCacheKey) {
+		if _, keyError := salesforceUtil.ParseTokenCacheKey(salesforceConf.TokenCacheKey); keyError != nil {
+			problems.add(section+".TokenCacheKey", keyError.Error())
+		}
+	}
+	loginURL, loginError := salesforceUtil.LoginURL(salesforceConf.TargetURI, salesforceConf.MyDomain, salesforceConf.Environment)
+	// TargetURI and MyDomain are checked the way the login host is built from them, a bare host being accepted
+	switch {
+	case loginError == nil:
+	case salesforceConf.TargetURI != "":
+		problems.add(section+".TargetURI", loginError.Error())
+	case salesforceConf.MyDomain != "":
+		problems.add(section+".MyDomain", loginError.Error())
+	}
+	problems.require(section+".ClientId", salesforceConf.ClientId)
+
+	switch salesforceConf.GrantType {
+	case "", salesforceUtil.GrantTypePassword:
+		problems.require(section+".Username", salesforceConf.Username)
+		problems.require(section+".Password", salesforceConf.Password)
+		problems.require(section+".ClientSecret", salesforceConf.ClientSecret)
+	case salesforceUtil.GrantTypeJWTBearer:
+		problems.require(section+".Username", salesforceConf.Username)
+		// an unresolved reference (ie. with validate-config) can only be checked for its syntax
+		if problems.require(section+".PrivateKey", salesforceConf.PrivateKey) && !isSecretReference(salesforceConf.PrivateKey) {
+			if _, keyError := salesforceUtil.ParsePrivateKey(salesforceConf.PrivateKey); keyError != nil {
+				problems.add(section+".PrivateKey", keyError.Error())
+			}
+		}
+	case salesforceUtil.GrantTypeRefreshToken:
+		problems.require(section+".RefreshToken", salesforceConf.RefreshToken)
+	case salesforceUtil.GrantTypeClientCredentials:
+		problems.require(section+".ClientSecret", salesforceConf.ClientSecret)
+		if loginError == nil && salesforceUtil.IsGenericLoginHost(loginURL) {
+			problems.add(section+".MyDomain", "is required by the client_credentials grant, the generic login hosts are not accepted")
+		}
+	default:
+		problems.add(section+".GrantType", "must be one of \"password\", \"jwt_bearer\", \"refresh_token\" or \"client_credentials\"")
+	}
+}
+
+func (timeouts TimeoutsConfiguration) validate(section string, problems *ConfigurationErrors) {
+	phases := map[string]Duration{
+		"Authentication": timeouts.Authentication,
+		"Export_page":    timeouts.Export_page,
+		"Download":       timeouts.Download,
+		"Upload":         timeouts.Upload,
+		"Object_export":  timeouts.Object_export,
+	}
+	for _, phase := range []string{"Authentication", "Export_page", "Download", "Upload", "Object_export"} {
+		if phases[phase] < 0 {
+			problems.add(section+"."+phase, "must not be negative")
+		}
+	}
+}
+
+func (httpConf HTTPConfiguration) validate(section string, problems *ConfigurationErrors) {
+	if httpConf.Proxy_url != "" && !isSecretReference(httpConf.Proxy_url) {
+		proxyURL, parseError := url.Parse(httpConf.Proxy_url)
+		if parseError != nil || proxyURL.Host == "" || (proxyURL.Scheme != "http" && proxyURL.Scheme != "https" && proxyURL.Scheme != "socks5") {
+			problems.add(section+".Proxy_url", "must be an http, https or socks5 URL like \"http://proxy.example.com:3128\"")
+		}
+	}
+	validateReadableFile(section+".Ca_file", httpConf.Ca_file, problems)
+	validateReadableFile(section+".Client_cert_file", httpConf.Client_cert_file, problems)
+	validateReadableFile(section+".Client_key_file", httpConf.Client_key_file, problems)
+	if (httpConf.Client_cert_file == "") != (httpConf.Client_key_file == "") {
+		problems.add(section+".Client_cert_file", "must be given together with Client_key_file")
+	}
+	if httpConf.Dial_timeout < 0 || httpConf.Tls_handshake_timeout < 0 || httpConf.Response_header_timeout < 0 || httpConf.Idle_conn_timeout < 0 || httpConf.Keep_alive < 0 {
+		problems.add(section, "timeouts must not be negative")
+	}
+	if httpConf.Max_idle_conns_per_host < 0 {
+		problems.add(section+".Max_idle_conns_per_host", "must not be negative")
+	}
+	switch httpConf.Tls_min_version {
+	case "", "1.0", "1.1", "1.2", "1.3":
+	default:
+		problems.add(section+".Tls_min_version", "must be one of \"1.0\", \"1.1\", \"1.2\" or \"1.3\"")
+	}
+}
+
+func (retryConf RetryConfiguration) validate(section string, problems *ConfigurationErrors) {
+	if retryConf.Max_attempts < 0 {
+		problems.add(section+".Max_attempts", "must not be negative")
+	}
+	if retryConf.Initial_backoff < 0 || retryConf.Max_backoff < 0 {
+		problems.add(section, "backoffs must not be negative")
+	} else if retryConf.Max_backoff > 0 && retryConf.Initial_backoff > retryConf.Max_backoff {
+		problems.add(section+".Initial_backoff", "must not be greater than Max_backoff")
+	}
+	if retryConf.Jitter_percent < 0 || retryConf.Jitter_percent > 100 {
+		problems.add(section+".Jitter_percent", "must be between 0 and 100")
+	}
+	for _, statusCode := range splitList(retryConf.Retryable_status_codes) {
+		if parsedStatusCode, parseError := strconv.Atoi(statusCode); parseError != nil || parsedStatusCode < 100 || parsedStatusCode > 599 {
+			problems.add(section+".Retryable_status_codes", fmt.Sprintf("%q is not an HTTP status code", statusCode))
+		}
+	}
+	for _, faultCode := range splitList(retryConf.Retryable_fault_codes) {
+		if !faultCodePattern.MatchString(faultCode) {
+			problems.add(section+".Retryable_fault_codes", fmt.Sprintf("%q is not a fault code like \"REQUEST_LIMIT_EXCEEDED\"", faultCode))
+		}
+	}
+}
+
+func (exportConf ExportConfiguration) validate(section string, problems *ConfigurationErrors) {
+	for _, field := range []struct{ name, list string }{{"Objects", exportConf.Objects}, {"Exclude_objects", exportConf.Exclude_objects}, {"Include_deleted", exportConf.Include_deleted}} {
+		for _, objectName := range splitList(field.list) {
+			if !objectNamePattern.MatchString(objectName) && !(field.name == "Include_deleted" && objectName == "*") {
+				problems.add(section+"."+field.name, fmt.Sprintf("%q is not an object name like \"Account\" or \"Invoice__c\"", objectName))
+			}
+		}
+	}
+	if exportConf.Batch_size != 0 && (exportConf.Batch_size < 200 || exportConf.Batch_size > 2000) {
+		problems.add(section+".Batch_size", "must be between 200 and 2000")
+	}
+}
+
+func (awsConf AWSConfiguration) validate(section string, problems *ConfigurationErrors) {
+	if awsConf.Instance_url != "" {
+		validateHttpsURL(section+".Instance_url", awsConf.Instance_url, problems)
+	}
+
+	if problems.require(section+".Region", awsConf.Region) && !awsRegionPattern.MatchString(awsConf.Region) {
+		problems.add(section+".Region", "\""+awsConf.Region+"\" is not a valid AWS region name")
+	}
+
+	if awsConf.S3_destination_bucket != "" {
+		validateBucketName(section+".s3_destination_bucket", awsConf.S3_destination_bucket, problems)
+	}
+	if strings.HasPrefix(awsConf.S3_destination_path, "/") {
+		problems.add(section+".s3_destination_path", "must not start with \"/\"")
+	}
+
+	// credentials can come either from a named profile or from static keys, never both
+	staticKeys := awsConf.Access_key_ID != "" || awsConf.Secret_access_key != ""
+	if awsConf.Profile != "" && staticKeys {
+		problems.add(section+".Profile", "conflicts with Access_key_ID/Secret_access_key, use either a profile or static keys")
+	}
+	if staticKeys && (awsConf.Access_key_ID == "" || awsConf.Secret_access_key == "") {
+		problems.add(section+".Access_key_ID", "Access_key_ID and Secret_access_key must be set together")
+	}
+	if awsConf.Session_token != "" && !staticKeys {
+		problems.add(section+".Session_token", "requires Access_key_ID and Secret_access_key")
+	}
+
+	webIdentity := awsConf.Web_identity_role_arn != "" || awsConf.Web_identity_token_file != ""
+	if awsConf.Credentials_source == credentialsSourceAuto && webIdentity && (staticKeys || awsConf.Profile != "") {
+		problems.add(section+".Web_identity_token_file", "conflicts with Profile and static keys")
+	}
+	// checked on the source actually used, an empty Credentials_source picking one from the fields set
+	switch awsConf.credentialsSource() {
+	case credentialsSourceAuto:
+	case credentialsSourceStatic:
+		if !staticKeys {
+			problems.add(section+".Credentials_source", "\"static\" requires Access_key_ID and Secret_access_key")
+		}
+	case credentialsSourceProfile:
+		if awsConf.Profile == "" {
+			problems.add(section+".Credentials_source", "\"profile\" requires Profile")
+		}
+	case credentialsSourceWebIdentity:
+		roleArn, tokenFile := awsConf.webIdentity()
+		switch {
+		case roleArn != "" && tokenFile != "":
+		case awsConf.Credentials_source == credentialsSourceWebIdentity:
+			problems.add(section+".Credentials_source", "\"web_identity\" requires Web_identity_role_arn and Web_identity_token_file (or AWS_ROLE_ARN and AWS_WEB_IDENTITY_TOKEN_FILE)")
+		case roleArn == "":
+			problems.add(section+".Web_identity_role_arn", "is required by Web_identity_token_file (or AWS_ROLE_ARN)")
+		default:
+			problems.add(section+".Web_identity_token_file", "is required by Web_identity_role_arn (or AWS_WEB_IDENTITY_TOKEN_FILE)")
+		}
+	case credentialsSourceInstanceRole:
+	default:
+		problems.add(section+".Credentials_source", "must be one of \"static\", \"profile\", \"web_identity\", \"instance_role\" or empty")
+	}
+	if awsConf.Credentials_source != credentialsSourceAuto {
+		if staticKeys && awsConf.Credentials_source != credentialsSourceStatic {
+			problems.add(section+".Credentials_source", "conflicts with Access_key_ID/Secret_access_key")
+		}
+		if awsConf.Profile != "" && awsConf.Credentials_source != credentialsSourceProfile {
+			problems.add(section+".Credentials_source", "conflicts with Profile")
+		}
+	}
+
+	if awsConf.Role_arn != "" {
+		validateRoleArn(section+".Role_arn", awsConf.Role_arn, problems)
+	} else if awsConf.External_id != "" || awsConf.Role_duration != 0 {
+		problems.add(section+".Role_arn", "is required by External_id and Role_duration")
+	}
+	if awsConf.Role_session_name != "" && !roleSessionNamePattern.MatchString(awsConf.Role_session_name) {
+		problems.add(section+".Role_session_name", "must be 2 to 64 characters among letters, numbers and =,.@_-")
+	}
+	if awsConf.Role_duration != 0 && (time.Duration(awsConf.Role_duration) < 15*time.Minute || time.Duration(awsConf.Role_duration) > 12*time.Hour) {
+		problems.add(section+".Role_duration", "must be between 15m and 12h")
+	}
+	if awsConf.Web_identity_role_arn != "" {
+		validateRoleArn(section+".Web_identity_role_arn", awsConf.Web_identity_role_arn, problems)
+	}
+}
+
+func validateRoleArn(field string, roleArn string, problems *ConfigurationErrors) {
+	if !roleArnPattern.MatchString(roleArn) {
+		problems.add(field, "\""+roleArn+"\" is not a valid IAM role ARN")
+	}
+}
+
+// validateReadableFile reports a file given in the configuration that can't be opened
+func validateReadableFile(field string, fileName string, problems *ConfigurationErrors) {
+	if fileName == "" {
+		return
+	}
+	file, openError := os.Open(fileName)
+	if openError != nil {
+		problems.add(field, fmt.Sprintf("can't be read: %v", openError))
+		return
+	}
+	file.Close()
+}
+
+func validateHttpsURL(field string, rawURL string, problems *ConfigurationErrors) {
+	parsedURL, parseError := url.Parse(rawURL)
+	if parseError != nil {
+		problems.add(field, "is not a valid URL: "+parseError.Error())
+		return
+	}
+	if parsedURL.Scheme != "https" {
+		problems.add(field, "must be an https:// URL")
+	}
+	if parsedURL.Host == "" {
+		problems.add(field, "must include a host name")
+	}
+}
+
+// validateBucketName applies the S3 bucket naming rules
+// https://docs.aws.amazon.com/AmazonS3/latest/userguide/bucketnamingrules.html
+func validateBucketName(field string, bucketName string, problems *ConfigurationErrors) {
+	switch {
+	case len(bucketName) < 3 || len(bucketName) > 63:
+		problems.add(field, "must be between 3 and 63 characters long")
+	case !bucketNamePattern.MatchString(bucketName):
+		problems.add(field, "can only contain lowercase letters, numbers, dots and hyphens and must begin and end with a letter or number")
+	case strings.Contains(bucketName, ".."):
+		problems.add(field, "must not contain two adjacent periods")
+	case net.ParseIP(bucketName) != nil:
+		problems.add(field, "must not be formatted as an IP address")
+	case strings.HasPrefix(bucketName, "xn--") || strings.HasPrefix(bucketName, "sthree-"):
+		problems.add(field, "must not start with the reserved prefixes \"xn--\" or \"sthree-\"")
+	case strings.HasSuffix(bucketName, "-s3alias") || strings.HasSuffix(bucketName, "--ol-s3"):
+		problems.add(field, "must not end with the reserved suffixes \"-s3alias\" or \"--ol-s3\"")
+	}
+}
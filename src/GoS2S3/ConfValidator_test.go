@@ -0,0 +1,53 @@
+package main
+
+import "testing"
+
+func TestValidateTargetURI(t *testing.T) {
+	tests := []struct {
+		targetURI string
+		valid     bool
+	}{
+		{"https://login.salesforce.com", true},
+		{"login.salesforce.com", true},
+		{"acme.my.salesforce.com/", true},
+		{"http://login.salesforce.com", false},
+		{"https://", false},
+	}
+	for _, test := range tests {
+		salesforceConf := SalesforceConfiguration{TargetURI: test.targetURI, ClientId: "client", Username: "user", Password: "password"}
+		var problems ConfigurationErrors
+		salesforceConf.validate("Salesforce", &problems)
+		reported := false
+		for _, problem := range problems {
+			if problem.Field == "Salesforce.TargetURI" {
+				reported = true
+			}
+		}
+		if reported == test.valid {
+			t.Errorf("TargetURI %q: problems %v, want valid=%v", test.targetURI, problems, test.valid)
+		}
+	}
+}
+
+func TestValidateAutomaticWebIdentity(t *testing.T) {
+	awsConf := AWSConfiguration{Region: "eu-west-3", Web_identity_role_arn: "arn:aws:iam::123456789012:role/backup"}
+	hasProblem := func(field string) bool {
+		var problems ConfigurationErrors
+		awsConf.validate("AWS", &problems)
+		for _, problem := range problems {
+			if problem.Field == field {
+				return true
+			}
+		}
+		return false
+	}
+
+	t.Setenv("AWS_WEB_IDENTITY_TOKEN_FILE", "")
+	if !hasProblem("AWS.Web_identity_token_file") {
+		t.Error("a role without token file must be reported when web identity is picked automatically")
+	}
+	t.Setenv("AWS_WEB_IDENTITY_TOKEN_FILE", "/var/run/secrets/eks.amazonaws.com/serviceaccount/token")
+	if hasProblem("AWS.Web_identity_token_file") {
+		t.Error("the token file set by EKS must be accepted")
+	}
+}
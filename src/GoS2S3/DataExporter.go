@@ -0,0 +1,278 @@
+package main
+
+import (
+	"GoS2S3/SalesforceWSDL"
+	"GoS2S3/httpUtil"
+	"GoS2S3/salesforceUtil"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// exportOrgData queries the objects of the Export section and uploads one CSV file per object,
+// unlike the weekly data export it can run at any time
+func exportOrgData(ctx context.Context, org OrgConfiguration, amazonConfiguration AWSConfiguration, run backupRun) (result orgBackupResult) {
+	result.Name = org.Name
+
+	activeSalesforceConnection, logout, connectionError := connectOrg(ctx, org, run)
+	if connectionError != nil {
+		result.Err = connectionError
+		return
+	}
+	defer logout()
+
+	objectNames, listingError := exportedObjects(ctx, activeSalesforceConnection, run.export)
+	if listingError != nil {
+		result.Err = fmt.Errorf("listing the objects to export: %w", listingError)
+		return
+	}
+	log.Printf("[%s] %d objects to export", org.Name, len(objectNames))
+
+	destinationFolder := filepath.Join("tmp", org.Name)
+	creationError := os.MkdirAll(destinationFolder, 0777)
+	if creationError != nil {
+		result.Err = fmt.Errorf("creating destination folder: %w", creationError)
+		return
+	}
+
+	for index, objectName := range objectNames {
+		if ctx.Err() != nil {
+			result.Err = fmt.Errorf("export interrupted: %w", ctx.Err())
+			result.FilesFailed += len(objectNames) - index
+			break
+		}
+		fileName, exportError := exportObject(ctx, activeSalesforceConnection, objectName, run.export.includesDeleted(objectName), destinationFolder, run)
+		if exportError != nil && run.export.Objects == "" && isObjectRefused(exportError, run.retryPolicy) {
+			log.Printf("[%s] Skipping object %s: %v", org.Name, objectName, exportError)
+			continue
+		}
+		if exportError == nil {
+			exportError = shipFile(ctx, fileName, amazonConfiguration, destinationFolder, run)
+		}
+		if exportError != nil {
+			log.Printf("[%s] Error while exporting object %s: %v", org.Name, objectName, exportError)
+			result.FilesFailed++
+		} else {
+			result.FilesTransferred++
+		}
+	}
+
+	return
+}
+
+// isObjectRefused tells if Salesforce answered the describe or the query of a discovered object with a fault
+// about the object itself, ie. MALFORMED_QUERY for ContentDocumentLink which needs a filter, INVALID_TYPE
+// or INVALID_TYPE_FOR_OPERATION. Session faults and the faults still retryable once the attempts are
+// exhausted concern the whole org and are not.
+func isObjectRefused(exportError error, retryPolicy httpUtil.RetryPolicy) bool {
+	var fault *SalesforceWSDL.SOAPFault
+	return errors.As(exportError, &fault) && !salesforceUtil.IsSessionExpired(exportError) && !retryPolicy.Retryable(exportError)
+}
+
+// exportedObjects returns the configured objects or, when none is configured, every queryable
+// object of the org; the excluded objects are left out of both
+func exportedObjects(ctx context.Context, salesforceConnection *salesforceUtil.SF_connection, exportConf ExportConfiguration) ([]string, error) {
+	candidates := splitList(exportConf.Objects)
+	if len(candidates) == 0 {
+		var describeResponse *SalesforceWSDL.DescribeGlobalResponse
+		describeError := salesforceConnection.WithSession(ctx, func() (callError error) {
+			describeResponse, callError = salesforceConnection.Soap.DescribeGlobalContext(ctx, &SalesforceWSDL.DescribeGlobal{})
+			return
+		})
+		if describeError != nil {
+			return nil, describeError
+		}
+		if describeResponse.Result == nil {
+			return nil, errors.New("describeGlobal returned no result")
+		}
+		for _, sobject := range describeResponse.Result.Sobjects {
+			if sobject.Queryable && !sobject.DeprecatedAndHidden {
+				candidates = append(candidates, sobject.Name)
+			}
+		}
+	}
+
+	// object names are case insensitive
+	excluded := make(map[string]bool)
+	for _, objectName := range splitList(exportConf.Exclude_objects) {
+		excluded[strings.ToLower(objectName)] = true
+	}
+	objectNames := make([]string, 0, len(candidates))
+	for _, objectName := range candidates {
+		if !excluded[strings.ToLower(objectName)] {
+			objectNames = append(objectNames, objectName)
+		}
+	}
+	return objectNames, nil
+}
+
+// exportObject writes every record of the object in destinationFolder as <object>.csv,
+// the columns following the field order of DescribeSObject. A failed export leaves no file.
+// With includeDeleted the records in the recycle bin and the archived ones are exported too.
+func exportObject(ctx context.Context, salesforceConnection *salesforceUtil.SF_connection, objectName string, includeDeleted bool, destinationFolder string, run backupRun) (fileName string, exportError error) {
+	if includeDeleted {
+		log.Printf("Exporting object: %s (deleted and archived records included)", objectName)
+	} else {
+		log.Printf("Exporting object: %s", objectName)
+	}
+	objectContext, cancelObject := run.timeouts.Object_export.withTimeout(ctx)
+	defer cancelObject()
+
+	columns, describeError := exportedFields(objectContext, salesforceConnection, objectName)
+	if describeError != nil {
+		return "", describeError
+	}
+	if includeDeleted {
+		columns = withDeletedColumn(objectName, columns)
+	}
+
+	fileName = objectName + ".csv"
+	filePath := filepath.Join(destinationFolder, fileName)
+	csvFile, creationError := os.Create(filePath)
+	if creationError != nil {
+		return fileName, creationError
+	}
+	defer csvFile.Close()
+
+	writer := csv.NewWriter(csvFile)
+	writer.Write(columns)
+	soql := "SELECT " + strings.Join(columns, ", ") + " FROM " + objectName
+	records, exportError := queryRecords(objectContext, salesforceConnection, soql, includeDeleted, run.export.Batch_size, func(record *SalesforceWSDL.Record) error {
+		row := make([]string, len(columns))
+		for index, column := range columns {
+			// nil fields are written as empty values
+			row[index], _ = record.Value(column)
+		}
+		return writer.Write(row)
+	})
+	if exportError == nil {
+		writer.Flush()
+		exportError = writer.Error()
+	}
+	if exportError == nil {
+		exportError = csvFile.Close()
+	}
+	if exportError != nil {
+		csvFile.Close()
+		os.Remove(filePath)
+		return fileName, exportError
+	}
+
+	log.Printf("%d records of %s exported", records, objectName)
+	return fileName, nil
+}
+
+// field telling the records in the recycle bin apart from the others
+const deletedField = "IsDeleted"
+
+// exportedFields lists the fields of the object in the order of DescribeSObject
+func exportedFields(ctx context.Context, salesforceConnection *salesforceUtil.SF_connection, objectName string) ([]string, error) {
+	var describeResponse *SalesforceWSDL.DescribeSObjectResponse
+	describeError := salesforceConnection.WithSession(ctx, func() (callError error) {
+		describeResponse, callError = salesforceConnection.Soap.DescribeSObjectContext(ctx, &SalesforceWSDL.DescribeSObject{SObjectType: objectName})
+		return
+	})
+	if describeError != nil {
+		return nil, describeError
+	}
+	if describeResponse.Result == nil {
+		return nil, fmt.Errorf("describeSObject of %s returned no result", objectName)
+	}
+
+	fieldNames := make([]string, 0, len(describeResponse.Result.Fields))
+	for _, field := range describeResponse.Result.Fields {
+		if field.Type_ != nil {
+			switch *field.Type_ {
+			// the compound fields duplicate their components, which are exported on their own,
+			// and the base64 ones can only be queried one record at a time
+			case SalesforceWSDL.FieldTypeAddress, SalesforceWSDL.FieldTypeLocation, SalesforceWSDL.FieldTypeBase64:
+				continue
+			}
+		}
+		fieldNames = append(fieldNames, field.Name)
+	}
+	return fieldNames, nil
+}
+
+// queryRecords runs the query, through QueryAll when includeDeleted is set, and its queryMore calls,
+// handing every record to handle as the responses are read. batchSize, when not 0, sets the records
+// returned by each call.
+func queryRecords(ctx context.Context, salesforceConnection *salesforceUtil.SF_connection, soql string, includeDeleted bool, batchSize int, handle func(record *SalesforceWSDL.Record) error) (records int, queryError error) {
+	onRecord := SalesforceWSDL.RecordHandlerFor(func(record *SalesforceWSDL.Record) error {
+		records++
+		return handle(record)
+	})
+	// a renewed session comes with a new client, the query options are set again on each call
+	soapClient := func() *SalesforceWSDL.Soap {
+		if batchSize > 0 {
+			salesforceConnection.Soap.SetQueryOptions(int32(batchSize))
+		}
+		return salesforceConnection.Soap
+	}
+
+	var result *SalesforceWSDL.QueryResult
+	queryError = salesforceConnection.WithSession(ctx, func() error {
+		if includeDeleted {
+			response, callError := soapClient().QueryAllStreamContext(ctx, &SalesforceWSDL.QueryAll{QueryString: soql}, onRecord)
+			if callError != nil {
+				return callError
+			}
+			result = response.Result
+			return nil
+		}
+		response, callError := soapClient().QueryStreamContext(ctx, &SalesforceWSDL.Query{QueryString: soql}, onRecord)
+		if callError != nil {
+			return callError
+		}
+		result = response.Result
+		return nil
+	})
+	for queryError == nil && result != nil && !result.Done && result.QueryLocator != nil {
+		queryLocator := result.QueryLocator
+		queryError = salesforceConnection.WithSession(ctx, func() error {
+			response, callError := soapClient().QueryMoreStreamContext(ctx, &SalesforceWSDL.QueryMore{QueryLocator: queryLocator}, onRecord)
+			if callError != nil {
+				return callError
+			}
+			result = response.Result
+			return nil
+		})
+	}
+	return records, queryError
+}
+
+// withDeletedColumn moves the IsDeleted column right after the Id one, so that the deleted
+// records stand out; objects without a recycle bin have no such field and are left as they are
+func withDeletedColumn(objectName string, columns []string) []string {
+	deletedIndex := -1
+	for index, column := range columns {
+		if strings.EqualFold(column, deletedField) {
+			deletedIndex = index
+		}
+	}
+	if deletedIndex < 0 {
+		log.Printf("%s has no %s field, its deleted records can't be told apart", objectName, deletedField)
+		return columns
+	}
+
+	ordered := make([]string, 0, len(columns))
+	for index, column := range columns {
+		if index == deletedIndex {
+			continue
+		}
+		ordered = append(ordered, column)
+		if strings.EqualFold(column, "Id") {
+			ordered = append(ordered, columns[deletedIndex])
+		}
+	}
+	if len(ordered) < len(columns) {
+		// no Id column, the IsDeleted one comes first
+		ordered = append([]string{columns[deletedIndex]}, ordered...)
+	}
+	return ordered
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"GoS2S3/SalesforceWSDL"
+	"GoS2S3/httpUtil"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestIsObjectRefused(t *testing.T) {
+	queryFault := func(code string) error {
+		return fmt.Errorf("query: %w", &SalesforceWSDL.SOAPFault{Code: code, String: code + ": refused"})
+	}
+	tests := []struct {
+		name    string
+		err     error
+		refused bool
+	}{
+		{"malformed query", queryFault("sf:MALFORMED_QUERY"), true},
+		{"invalid type", queryFault("sf:INVALID_TYPE"), true},
+		{"operation not supported", queryFault("sf:INVALID_TYPE_FOR_OPERATION"), true},
+		{"expired session", queryFault("sf:INVALID_SESSION_ID"), false},
+		{"request limit", queryFault("sf:REQUEST_LIMIT_EXCEEDED"), false},
+		{"network error", errors.New("connection reset by peer"), false},
+	}
+	for _, test := range tests {
+		if refused := isObjectRefused(test.err, httpUtil.DefaultRetryPolicy()); refused != test.refused {
+			t.Errorf("%s: isObjectRefused(%v) = %v, want %v", test.name, test.err, refused, test.refused)
+		}
+	}
+}
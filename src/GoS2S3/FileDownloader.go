@@ -1,16 +1,20 @@
 package main
 
 import (
+	"GoS2S3/httpUtil"
+	"GoS2S3/salesforceUtil"
+	"context"
 	"io"
 	"log"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
-func downloadFileFromUrl(targetFileUrl string, parameters map[string]interface{}) (fileName string, someError error) {
-	client := &http.Client{}
-
-	request, requestError := http.NewRequest("GET", targetFileUrl, nil)
+// downloadFileFromUrl saves the file in destinationFolder, a download interrupted through ctx leaves no partial file
+func downloadFileFromUrl(ctx context.Context, client *http.Client, targetFileUrl string, parameters map[string]interface{}, destinationFolder string) (fileName string, someError error) {
+	request, requestError := http.NewRequestWithContext(ctx, "GET", targetFileUrl, nil)
 	if requestError != nil {
 		log.Printf("Error creating the download request for %s: \n\t - %s", targetFileUrl, requestError)
 		someError = requestError
@@ -25,15 +29,6 @@ func downloadFileFromUrl(targetFileUrl string, parameters map[string]interface{}
 		request.AddCookie(&value)
 	}
 
-	// Create the file
-	downloadedFile, fileCreationErr := os.Create("tmp/" + fileName)
-	if fileCreationErr != nil {
-		log.Printf("Error creating the output file %s: \n\t - %s", fileName, fileCreationErr)
-		someError = fileCreationErr
-		return
-	}
-	defer downloadedFile.Close()
-
 	// Do the request
 	response, responseError := client.Do(request)
 	if responseError != nil {
@@ -43,10 +38,31 @@ func downloadFileFromUrl(targetFileUrl string, parameters map[string]interface{}
 	}
 	defer response.Body.Close()
 
+	// an expired session is redirected to the login page, which must not be saved as the export file
+	if response.StatusCode != http.StatusOK {
+		someError = &httpUtil.StatusError{Operation: "download of " + fileName, StatusCode: response.StatusCode, Status: response.Status}
+		return
+	}
+	if strings.HasPrefix(response.Header.Get("Content-Type"), "text/html") || salesforceUtil.LooksLikeLoginPage(response.Request.URL, nil) {
+		someError = &salesforceUtil.SessionExpiredError{Operation: "download of " + fileName, Reason: "redirected to the login page"}
+		return
+	}
+
+	// Create the file
+	filePath := filepath.Join(destinationFolder, fileName)
+	downloadedFile, fileCreationErr := os.Create(filePath)
+	if fileCreationErr != nil {
+		log.Printf("Error creating the output file %s: \n\t - %s", fileName, fileCreationErr)
+		someError = fileCreationErr
+		return
+	}
+	defer downloadedFile.Close()
+
 	// Write the body to file
 	_, savingError := io.Copy(downloadedFile, response.Body)
 	if savingError != nil {
 		log.Printf("Error while saving the file %s: \n\t - %s", fileName, savingError)
+		os.Remove(filePath)
 		someError = savingError
 		return
 	}
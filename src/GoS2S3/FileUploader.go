@@ -1,39 +1,93 @@
 package main
 
 import (
+	"GoS2S3/httpUtil"
+	"context"
+	"errors"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"strconv"
 )
 
-func uploadFileToS3(currentSession *session.Session, applicationConfiguration AWSConfiguration, filename string) (transferResult string, transferError error) {
+func uploadFileToS3(ctx context.Context, currentSession *session.Session, applicationConfiguration AWSConfiguration, sourceFolder string, filename string) (transferResult string, transferError error) {
 
 	// Create an uploader with the session and default options
 	uploader := s3manager.NewUploader(currentSession)
 
-	fileReader, readingError := os.Open("tmp/" + filename)
+	fileReader, readingError := os.Open(filepath.Join(sourceFolder, filename))
 	if readingError != nil {
 		log.Printf("Failed to open file %q, %v", filename, readingError)
 		return "Failed to open file " + filename, readingError
 	}
 	defer fileReader.Close()
 
+	// Obtain the credentials before starting, when a role is configured this is where it gets
+	// assumed (or refreshed if it is about to expire) so the upload doesn't start with a stale session.
+	if _, credentialsError := currentSession.Config.Credentials.GetWithContext(ctx); credentialsError != nil {
+		log.Printf("Failed to obtain AWS credentials, %v", credentialsError)
+		return "Failed to obtain AWS credentials", credentialsError
+	}
+
 	// Upload the file to S3.
-	uploadResult, uploadError := uploader.Upload(&s3manager.UploadInput{
+	uploadInput := &s3manager.UploadInput{
 		Bucket: aws.String(applicationConfiguration.S3_destination_bucket),
 		Key:    aws.String(applicationConfiguration.S3_destination_path + strconv.FormatInt(todayEpoch, 10) + "/" + applicationConfiguration.S3_destination_prefix + filename),
 		Body:   fileReader,
-	})
+	}
+	uploadResult, uploadError := uploader.UploadWithContext(ctx, uploadInput)
+	if uploadError != nil && awsErrorMatches(uploadError, request.IsErrorExpiredCreds) {
+		// the assumed role session expired in the middle of the transfer: force a new
+		// AssumeRole call and start the upload again from the beginning of the file
+		log.Printf("AWS credentials expired while uploading %s, refreshing them and retrying", filename)
+		currentSession.Config.Credentials.Expire()
+		if _, seekError := fileReader.Seek(0, io.SeekStart); seekError != nil {
+			return "Failed to upload file " + filename, seekError
+		}
+		uploadResult, uploadError = uploader.UploadWithContext(ctx, uploadInput)
+	}
 	if uploadError != nil {
 		log.Printf("Failed to upload file, %v", uploadError)
+		if request.IsErrorRetryable(uploadError) || awsErrorMatches(uploadError, request.IsErrorThrottle) {
+			// the SDK already retried each part, the retry policy starts the whole upload again;
+			// the retryable statuses of the failed part are also recognised by the policy itself
+			uploadError = httpUtil.MarkRetryable(uploadError)
+		}
 		return "Failed to upload file " + filename, uploadError
 	}
 	log.Printf("File uploaded to, %s\n", aws.StringValue(&uploadResult.Location))
 
-	os.Remove("tmp/" + filename)
+	os.Remove(filepath.Join(sourceFolder, filename))
 
 	return "Success", nil
 }
+
+// awsErrorMatches tells if err or one of its causes matches. s3manager reports a failed multipart upload,
+// ie. every export file over 5 MB, as a "MultipartUpload" error wrapping the error of the part.
+func awsErrorMatches(err error, matches func(error) bool) bool {
+	for err != nil {
+		if matches(err) {
+			return true
+		}
+		switch cause := err.(type) {
+		case awserr.BatchedErrors:
+			for _, originalError := range cause.OrigErrs() {
+				if awsErrorMatches(originalError, matches) {
+					return true
+				}
+			}
+			return false
+		case awserr.Error:
+			err = cause.OrigErr()
+		default:
+			err = errors.Unwrap(err)
+		}
+	}
+	return false
+}
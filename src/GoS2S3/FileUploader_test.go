@@ -0,0 +1,30 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"testing"
+)
+
+func TestAWSErrorMatchesExpiredCredentials(t *testing.T) {
+	expiredToken := awserr.NewRequestFailure(awserr.New("ExpiredToken", "The provided token has expired.", nil), 400, "request-id")
+	tests := []struct {
+		name    string
+		err     error
+		expired bool
+	}{
+		{"single part upload", expiredToken, true},
+		{"multipart upload", awserr.New("MultipartUpload", "upload multipart failed", expiredToken), true},
+		{"batched errors", awserr.NewBatchError("BatchedErrors", "multiple errors", []error{errors.New("part 1 aborted"), expiredToken}), true},
+		{"wrapped by the caller", fmt.Errorf("uploading: %w", awserr.New("MultipartUpload", "upload multipart failed", expiredToken)), true},
+		{"other part failure", awserr.New("MultipartUpload", "upload multipart failed", awserr.New("AccessDenied", "Access Denied", nil)), false},
+		{"no error", nil, false},
+	}
+	for _, test := range tests {
+		if expired := awsErrorMatches(test.err, request.IsErrorExpiredCreds); expired != test.expired {
+			t.Errorf("%s: awsErrorMatches(%v) = %v, want %v", test.name, test.err, expired, test.expired)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"GoS2S3/httpUtil"
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// name of the org built from the Salesforce section when no Orgs are configured
+const defaultOrgName = "default"
+
+// backupRun holds what the backups of every org share
+type backupRun struct {
+	httpClient    *http.Client
+	amazonSession *session.Session
+	retryPolicy   httpUtil.RetryPolicy
+	timeouts      TimeoutsConfiguration
+	export        ExportConfiguration
+}
+
+// orgBackup backs up a single org, either through the weekly data export or through queries
+type orgBackup func(ctx context.Context, org OrgConfiguration, amazonConfiguration AWSConfiguration, run backupRun) orgBackupResult
+
+type orgBackupResult struct {
+	Name             string
+	FilesTransferred int
+	FilesFailed      int
+	Err              error
+}
+
+func (result orgBackupResult) failed() bool {
+	return result.Err != nil || result.FilesFailed > 0
+}
+
+// orgProfiles returns the orgs to back up: the Orgs list or, when it is empty,
+// a single org built from the Salesforce section
+func (connectionsConf Configuration) orgProfiles() []OrgConfiguration {
+	if len(connectionsConf.Orgs) > 0 {
+		return connectionsConf.Orgs
+	}
+	return []OrgConfiguration{{Name: defaultOrgName, Salesforce: connectionsConf.Salesforce}}
+}
+
+// awsConfigurationFor returns the AWS section with the S3 destination overridden by the org ones
+func (connectionsConf Configuration) awsConfigurationFor(org OrgConfiguration) AWSConfiguration {
+	amazonConfiguration := connectionsConf.Amazon
+	if org.S3_destination_bucket != "" {
+		amazonConfiguration.S3_destination_bucket = org.S3_destination_bucket
+	}
+	if org.S3_destination_path != "" {
+		amazonConfiguration.S3_destination_path = org.S3_destination_path
+	}
+	if org.S3_destination_prefix != "" {
+		amazonConfiguration.S3_destination_prefix = org.S3_destination_prefix
+	}
+	return amazonConfiguration
+}
+
+// runBackups backs up every configured org with backup, ParallelOrgs at a time, logs a summary
+// and returns false if any org failed
+func runBackups(ctx context.Context, configuration Configuration, backup orgBackup) bool {
+
+	timestampEpoch = time.Now()
+	todayEpoch = timestampEpoch.Unix() - (timestampEpoch.Unix() % 86400)
+
+	// a single client, and its pool of connections, is shared by every org and by the AWS calls
+	httpClient, clientError := httpUtil.NewHTTPClient(configuration.HTTP.options())
+	if clientError != nil {
+		log.Printf("Error creating the HTTP client: %v", clientError)
+		return false
+	}
+
+	amazonSession, sessionError := newAmazonSession(configuration.Amazon, httpClient)
+	if sessionError != nil {
+		log.Printf("Error creating the Amazon session: %v", sessionError)
+		return false
+	}
+	log.Printf("Amazon session created (credentials: %s)", configuration.Amazon.credentialsDescription())
+
+	run := backupRun{
+		httpClient:    httpClient,
+		amazonSession: amazonSession,
+		retryPolicy:   configuration.Retry.policy(),
+		timeouts:      configuration.Timeouts,
+		export:        configuration.Export,
+	}
+
+	orgs := configuration.orgProfiles()
+	parallelOrgs := configuration.ParallelOrgs
+	if parallelOrgs < 1 {
+		parallelOrgs = 1
+	}
+
+	results := make([]orgBackupResult, len(orgs))
+	slots := make(chan struct{}, parallelOrgs)
+	var waitGroup sync.WaitGroup
+	for index, org := range orgs {
+		waitGroup.Add(1)
+		go func(index int, org OrgConfiguration) {
+			defer waitGroup.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			if ctx.Err() != nil {
+				results[index] = orgBackupResult{Name: org.Name, Err: fmt.Errorf("backup not started: %w", ctx.Err())}
+				return
+			}
+
+			// a failure in one org must not take down the backup of the others
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					results[index] = orgBackupResult{Name: org.Name, Err: fmt.Errorf("backup aborted: %v", recovered)}
+				}
+			}()
+
+			log.Printf("[%s] Starting backup", org.Name)
+			results[index] = backup(ctx, org, configuration.awsConfigurationFor(org), run)
+		}(index, org)
+	}
+	waitGroup.Wait()
+
+	successful := true
+	log.Println("Backup summary:")
+	for _, result := range results {
+		status := "SUCCESS"
+		if result.failed() {
+			status = "FAILED"
+			successful = false
+		}
+		log.Printf("\t- %s: %s (%d files transferred, %d failed)", result.Name, status, result.FilesTransferred, result.FilesFailed)
+		if result.Err != nil {
+			log.Printf("\t\t%v", result.Err)
+		}
+	}
+	return successful
+}
@@ -0,0 +1,175 @@
+package SalesforceWSDL
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// namespace of the xsi:type and xsi:nil attributes
+const xmlSchemaInstanceNamespace = "http://www.w3.org/2001/XMLSchema-instance"
+
+// Record is a record of any object, standard or custom, with every field returned by the API
+// in the order of the response. The typed structs of the WSDL only know the objects and fields
+// of the version it was generated from.
+type Record struct {
+	Type   string // object name taken from xsi:type, ie. "Account", empty when not given
+	Fields []*RecordField
+	index  map[string]*RecordField
+}
+
+// RecordField is a field of a Record. A relationship is returned as a nested Record,
+// a child relationship subquery as a RecordQueryResult.
+type RecordField struct {
+	Name        string
+	Type        string // xsi:type as sent, ie. "xsd:dateTime", empty when not given
+	Nil         bool
+	Value       string
+	Record      *Record
+	QueryResult *RecordQueryResult
+}
+
+// RecordQueryResult is the result of a child relationship subquery
+type RecordQueryResult struct {
+	Done         bool          `xml:"done"`
+	QueryLocator *QueryLocator `xml:"queryLocator"`
+	Records      []*Record     `xml:"records"`
+	Size         int32         `xml:"size"`
+}
+
+// Field returns the field called name, the first one when the response repeats it (ie. the Id), or nil
+func (record *Record) Field(name string) *RecordField {
+	if record == nil {
+		return nil
+	}
+	return record.index[strings.ToLower(name)]
+}
+
+// Value returns the value of a field, the fields of the related records being reached
+// through their relationship, ie. "Owner.Name". ok is false when the field is missing or nil.
+func (record *Record) Value(path string) (value string, ok bool) {
+	names := strings.Split(path, ".")
+	current := record
+	for _, name := range names[:len(names)-1] {
+		field := current.Field(name)
+		if field == nil || field.Record == nil {
+			return "", false
+		}
+		current = field.Record
+	}
+	field := current.Field(names[len(names)-1])
+	if field == nil || field.Nil || field.Record != nil || field.QueryResult != nil {
+		return "", false
+	}
+	return field.Value, true
+}
+
+// Names lists the fields in the order of the response
+func (record *Record) Names() []string {
+	names := make([]string, 0, len(record.Fields))
+	for _, field := range record.Fields {
+		names = append(names, field.Name)
+	}
+	return names
+}
+
+func (record *Record) add(field *RecordField) {
+	if record.index == nil {
+		record.index = make(map[string]*RecordField)
+	}
+	// the field names of the API are case insensitive
+	key := strings.ToLower(field.Name)
+	if _, found := record.index[key]; !found {
+		record.index[key] = field
+	}
+	record.Fields = append(record.Fields, field)
+}
+
+// UnmarshalXML reads the record element start and every field in it
+func (record *Record) UnmarshalXML(decoder *xml.Decoder, start xml.StartElement) error {
+	record.Type = objectName(schemaInstanceAttribute(start, "type"))
+	return record.decodeFields(decoder, nil)
+}
+
+// decodeFields reads the fields up to the end of the record, starting with first when the caller
+// already read the start of the first field
+func (record *Record) decodeFields(decoder *xml.Decoder, first *xml.StartElement) error {
+	if first != nil {
+		if err := record.decodeField(decoder, *first); err != nil {
+			return err
+		}
+	}
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch element := token.(type) {
+		case xml.StartElement:
+			if err = record.decodeField(decoder, element); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// decodeField reads a field element, which holds either a value, a related record
+// or the records of a subquery
+func (record *Record) decodeField(decoder *xml.Decoder, start xml.StartElement) error {
+	field := &RecordField{Name: start.Name.Local, Type: schemaInstanceAttribute(start, "type")}
+	record.add(field)
+
+	if schemaInstanceAttribute(start, "nil") == "true" {
+		field.Nil = true
+		return decoder.Skip()
+	}
+	if objectName(field.Type) == "QueryResult" {
+		field.QueryResult = new(RecordQueryResult)
+		return decoder.DecodeElement(field.QueryResult, &start)
+	}
+
+	var value strings.Builder
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch element := token.(type) {
+		case xml.CharData:
+			value.Write(element)
+		case xml.StartElement:
+			// a field holding fields is a related record
+			field.Record = &Record{Type: objectName(field.Type)}
+			return field.Record.decodeFields(decoder, &element)
+		case xml.EndElement:
+			field.Value = value.String()
+			return nil
+		}
+	}
+}
+
+func schemaInstanceAttribute(start xml.StartElement, name string) string {
+	for _, attribute := range start.Attr {
+		if attribute.Name.Space == xmlSchemaInstanceNamespace && attribute.Name.Local == name {
+			return attribute.Value
+		}
+	}
+	return ""
+}
+
+// objectName strips the namespace prefix of an xsi:type, ie. "sf:Account" gives "Account"
+func objectName(schemaType string) string {
+	return schemaType[strings.LastIndex(schemaType, ":")+1:]
+}
+
+// RecordHandlerFor adapts a handler of decoded records to the streamed query calls
+func RecordHandlerFor(handle func(record *Record) error) RecordHandler {
+	return func(decoder *xml.Decoder, start xml.StartElement) error {
+		record := new(Record)
+		if err := decoder.DecodeElement(record, &start); err != nil {
+			return err
+		}
+		return handle(record)
+	}
+}
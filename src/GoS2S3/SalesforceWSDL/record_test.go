@@ -0,0 +1,148 @@
+package SalesforceWSDL
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+// body of an enterprise query response with relationships, a child subquery, nil fields
+// and the Id repeated as some API calls return it
+const contactsQueryResponse = `<queryResponse xmlns="urn:enterprise.soap.sforce.com" xmlns:sf="urn:sobject.enterprise.soap.sforce.com" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+<result xsi:type="QueryResult">
+<done>true</done>
+<queryLocator xsi:nil="true"/>
+<records xsi:type="sf:Contact">
+	<sf:Id>0035g00000AbCdEAAV</sf:Id>
+	<sf:Id>0035g00000AbCdEAAV</sf:Id>
+	<sf:Email xsi:nil="true"/>
+	<sf:LastName>Doe</sf:LastName>
+	<sf:Owner xsi:type="sf:User">
+		<sf:Id xsi:nil="true"/>
+		<sf:Name>Jane Admin</sf:Name>
+	</sf:Owner>
+	<sf:Account xsi:type="sf:Account">
+		<sf:Id>0015g00000XyZaBAAV</sf:Id>
+		<sf:Owner xsi:type="sf:User"><sf:Id xsi:nil="true"/><sf:Name>Bob Sales</sf:Name></sf:Owner>
+	</sf:Account>
+	<sf:Cases xsi:type="QueryResult">
+		<done>true</done>
+		<queryLocator xsi:nil="true"/>
+		<records xsi:type="sf:Case"><sf:Id>5005g00000CaSeAAAV</sf:Id><sf:Subject>Broken</sf:Subject></records>
+		<records xsi:type="sf:Case"><sf:Id>5005g00000CaSfAAAV</sf:Id><sf:Subject>Slow</sf:Subject></records>
+		<size>2</size>
+	</sf:Cases>
+	<sf:Description>Line 1 &amp; line 2</sf:Description>
+	<sf:Birthdate xsi:type="xsd:date">1980-05-17</sf:Birthdate>
+</records>
+<records xsi:type="sf:Contact">
+	<sf:Id>0035g00000AbCdFAAV</sf:Id>
+	<sf:LastName>Roe</sf:LastName>
+	<sf:Owner xsi:nil="true"/>
+	<sf:Cases xsi:nil="true"/>
+</records>
+<size>2</size>
+</result>
+</queryResponse>`
+
+const accountRetrieveResponse = `<retrieveResponse xmlns="urn:enterprise.soap.sforce.com" xmlns:sf="urn:sobject.enterprise.soap.sforce.com" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+<result xsi:type="sf:Account"><sf:Id>0015g00000XyZaBAAV</sf:Id><sf:Name>Acme</sf:Name><sf:AnnualRevenue xsi:nil="true"/></result>
+</retrieveResponse>`
+
+func TestRecordValues(t *testing.T) {
+	var response QueryResponse
+	if decodeError := xml.Unmarshal([]byte(contactsQueryResponse), &response); decodeError != nil {
+		t.Fatal(decodeError)
+	}
+	if response.Result == nil || len(response.Result.Records) != 2 {
+		t.Fatalf("decoded %+v, want 2 records", response.Result)
+	}
+	first, second := response.Result.Records[0], response.Result.Records[1]
+
+	tests := []struct {
+		record *Record
+		path   string
+		value  string
+		ok     bool
+	}{
+		{first, "Id", "0035g00000AbCdEAAV", true},
+		{first, "ID", "0035g00000AbCdEAAV", true},
+		{first, "LastName", "Doe", true},
+		{first, "Email", "", false},
+		{first, "Description", "Line 1 & line 2", true},
+		{first, "Birthdate", "1980-05-17", true},
+		{first, "Owner.Name", "Jane Admin", true},
+		{first, "owner.name", "Jane Admin", true},
+		{first, "Owner.Id", "", false},
+		{first, "Account.Owner.Name", "Bob Sales", true},
+		{first, "Owner", "", false},
+		{first, "Cases", "", false},
+		{first, "Phone", "", false},
+		{first, "Phone.Name", "", false},
+		{second, "LastName", "Roe", true},
+		{second, "Owner.Name", "", false},
+		{second, "Cases", "", false},
+	}
+	for _, test := range tests {
+		value, ok := test.record.Value(test.path)
+		if value != test.value || ok != test.ok {
+			t.Errorf("%s Value(%q) = %q, %v, want %q, %v", test.record.Names(), test.path, value, ok, test.value, test.ok)
+		}
+	}
+}
+
+func TestRecordStructure(t *testing.T) {
+	var response QueryResponse
+	if decodeError := xml.Unmarshal([]byte(contactsQueryResponse), &response); decodeError != nil {
+		t.Fatal(decodeError)
+	}
+	record := response.Result.Records[0]
+
+	if record.Type != "Contact" {
+		t.Errorf("Type = %q, want Contact", record.Type)
+	}
+	if names := strings.Join(record.Names(), ","); names != "Id,Id,Email,LastName,Owner,Account,Cases,Description,Birthdate" {
+		t.Errorf("Names() = %s, want the fields in the order of the response", names)
+	}
+	if field := record.Field("email"); field == nil || !field.Nil {
+		t.Errorf("Field(email) = %+v, want a nil field", field)
+	}
+	if field := record.Field("Birthdate"); field == nil || field.Type != "xsd:date" {
+		t.Errorf("Field(Birthdate) = %+v, want the xsi:type kept", field)
+	}
+	if owner := record.Field("Owner"); owner == nil || owner.Record == nil || owner.Record.Type != "User" {
+		t.Errorf("Field(Owner) = %+v, want a related User record", owner)
+	}
+
+	cases := record.Field("Cases")
+	if cases == nil || cases.QueryResult == nil {
+		t.Fatalf("Field(Cases) = %+v, want a subquery result", cases)
+	}
+	if !cases.QueryResult.Done || cases.QueryResult.Size != 2 || len(cases.QueryResult.Records) != 2 {
+		t.Errorf("Cases = %+v, want 2 records and done", cases.QueryResult)
+	}
+	if subject, _ := cases.QueryResult.Records[1].Value("Subject"); subject != "Slow" || cases.QueryResult.Records[1].Type != "Case" {
+		t.Errorf("second case = %s %q, want the Case \"Slow\"", cases.QueryResult.Records[1].Type, subject)
+	}
+
+	if second := response.Result.Records[1].Field("Owner"); second == nil || !second.Nil || second.Record != nil {
+		t.Errorf("nil Owner = %+v, want a nil field without record", second)
+	}
+}
+
+func TestRetrieveRecords(t *testing.T) {
+	var response RetrieveResponse
+	if decodeError := xml.Unmarshal([]byte(accountRetrieveResponse), &response); decodeError != nil {
+		t.Fatal(decodeError)
+	}
+	if len(response.Result) != 1 {
+		t.Fatalf("%d records, want 1", len(response.Result))
+	}
+	account := response.Result[0]
+	if name, ok := account.Value("Name"); account.Type != "Account" || name != "Acme" || !ok {
+		t.Errorf("retrieved %s %q, want the Account Acme", account.Type, name)
+	}
+	if _, ok := account.Value("AnnualRevenue"); ok {
+		t.Error("a nil field must not have a value")
+	}
+}
@@ -1,13 +1,17 @@
 package SalesforceWSDL
 
 import (
+	"GoS2S3/httpUtil"
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/xml"
-	"io/ioutil"
+	"fmt"
+	"io"
 	"log"
 	"net"
 	"net/http"
+	"reflect"
 	"time"
 )
 
@@ -14096,7 +14100,7 @@ type Retrieve struct {
 type RetrieveResponse struct {
 	XMLName xml.Name `xml:"urn:enterprise.soap.sforce.com retrieveResponse"`
 
-	Result []*SObject `xml:"result,omitempty"`
+	Result []*Record `xml:"result,omitempty"`
 }
 
 type ConvertLead struct {
@@ -14404,11 +14408,9 @@ type Location struct {
 }
 
 type QueryResult struct {
-	XMLName xml.Name `xml:"urn:enterprise.soap.sforce.com QueryResult"`
-
 	Done         bool          `xml:"done,omitempty"`
 	QueryLocator *QueryLocator `xml:"queryLocator,omitempty"`
-	Records      []*SObject    `xml:"records,omitempty"`
+	Records      []*Record     `xml:"records,omitempty"`
 	Size         int32         `xml:"size,omitempty"`
 }
 
@@ -14605,8 +14607,6 @@ type ResetPasswordResult struct {
 }
 
 type GetUserInfoResult struct {
-	XMLName xml.Name `xml:"urn:enterprise.soap.sforce.com userInfo"`
-
 	AccessibilityMode          bool   `xml:"accessibilityMode,omitempty"`
 	ChatterExternal            bool   `xml:"chatterExternal,omitempty"`
 	CurrencySymbol             string `xml:"currencySymbol,omitempty"`
@@ -14903,8 +14903,6 @@ type LeadConvertResult struct {
 }
 
 type DescribeSObjectResult struct {
-	XMLName xml.Name `xml:"urn:enterprise.soap.sforce.com DescribeSObjectResult"`
-
 	ActionOverrides       []*ActionOverride    `xml:"actionOverrides,omitempty"`
 	Activateable          bool                 `xml:"activateable,omitempty"`
 	ChildRelationships    []*ChildRelationship `xml:"childRelationships,omitempty"`
@@ -14944,8 +14942,6 @@ type DescribeSObjectResult struct {
 }
 
 type DescribeGlobalSObjectResult struct {
-	XMLName xml.Name `xml:"urn:enterprise.soap.sforce.com DescribeGlobalSObjectResult"`
-
 	Activateable        bool   `xml:"activateable,omitempty"`
 	Createable          bool   `xml:"createable,omitempty"`
 	Custom              bool   `xml:"custom,omitempty"`
@@ -14973,8 +14969,6 @@ type DescribeGlobalSObjectResult struct {
 }
 
 type ChildRelationship struct {
-	XMLName xml.Name `xml:"urn:enterprise.soap.sforce.com ChildRelationship"`
-
 	CascadeDelete       bool     `xml:"cascadeDelete,omitempty"`
 	ChildSObject        string   `xml:"childSObject,omitempty"`
 	DeprecatedAndHidden bool     `xml:"deprecatedAndHidden,omitempty"`
@@ -14986,8 +14980,6 @@ type ChildRelationship struct {
 }
 
 type DescribeGlobalResult struct {
-	XMLName xml.Name `xml:"urn:enterprise.soap.sforce.com DescribeGlobalResult"`
-
 	Encoding     string                         `xml:"encoding,omitempty"`
 	MaxBatchSize int32                          `xml:"maxBatchSize,omitempty"`
 	Sobjects     []*DescribeGlobalSObjectResult `xml:"sobjects,omitempty"`
@@ -15001,8 +14993,6 @@ type DescribeGlobalThemes struct {
 }
 
 type ScopeInfo struct {
-	XMLName xml.Name `xml:"urn:enterprise.soap.sforce.com ScopeInfo"`
-
 	Label string `xml:"label,omitempty"`
 	Name  string `xml:"name,omitempty"`
 }
@@ -15014,16 +15004,12 @@ type StringList struct {
 }
 
 type FilteredLookupInfo struct {
-	XMLName xml.Name `xml:"urn:enterprise.soap.sforce.com FilteredLookupInfo"`
-
 	ControllingFields []string `xml:"controllingFields,omitempty"`
 	Dependent         bool     `xml:"dependent,omitempty"`
 	OptionalFilter    bool     `xml:"optionalFilter,omitempty"`
 }
 
 type Field struct {
-	XMLName xml.Name `xml:"urn:enterprise.soap.sforce.com Field"`
-
 	Aggregatable                 bool                `xml:"aggregatable,omitempty"`
 	AiPredictionField            bool                `xml:"aiPredictionField,omitempty"`
 	AutoNumber                   bool                `xml:"autoNumber,omitempty"`
@@ -15084,8 +15070,6 @@ type Field struct {
 }
 
 type PicklistEntry struct {
-	XMLName xml.Name `xml:"urn:enterprise.soap.sforce.com PicklistEntry"`
-
 	Active       bool   `xml:"active,omitempty"`
 	DefaultValue bool   `xml:"defaultValue,omitempty"`
 	Label        string `xml:"label,omitempty"`
@@ -15622,14 +15606,10 @@ type CustomLinkComponent struct {
 }
 
 type NamedLayoutInfo struct {
-	XMLName xml.Name `xml:"urn:enterprise.soap.sforce.com NamedLayoutInfo"`
-
 	Name string `xml:"name,omitempty"`
 }
 
 type RecordTypeInfo struct {
-	XMLName xml.Name `xml:"urn:enterprise.soap.sforce.com RecordTypeInfo"`
-
 	Active                   bool   `xml:"active,omitempty"`
 	Available                bool   `xml:"available,omitempty"`
 	DefaultRecordTypeMapping bool   `xml:"defaultRecordTypeMapping,omitempty"`
@@ -15984,8 +15964,6 @@ type DescribeIcon struct {
 }
 
 type ActionOverride struct {
-	XMLName xml.Name `xml:"urn:enterprise.soap.sforce.com ActionOverride"`
-
 	FormFactor         string `xml:"formFactor,omitempty"`
 	IsAvailableInTouch bool   `xml:"isAvailableInTouch,omitempty"`
 	Name               string `xml:"name,omitempty"`
@@ -16925,8 +16903,12 @@ func NewSoap(url string, tls bool, auth *BasicAuth) *Soap {
 //   - InvalidIdFault
 /* Login to the Salesforce.com SOAP Api */
 func (service *Soap) Login(request *Login) (*LoginResponse, error) {
+	return service.LoginContext(context.Background(), request)
+}
+
+func (service *Soap) LoginContext(ctx context.Context, request *Login) (*LoginResponse, error) {
 	response := new(LoginResponse)
-	err := service.client.Call("Login", request, response)
+	err := service.client.CallContext(ctx, "Login", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -16940,8 +16922,12 @@ func (service *Soap) Login(request *Login) (*LoginResponse, error) {
 //   - UnexpectedErrorFault
 /* Describe an sObject */
 func (service *Soap) DescribeSObject(request *DescribeSObject) (*DescribeSObjectResponse, error) {
+	return service.DescribeSObjectContext(context.Background(), request)
+}
+
+func (service *Soap) DescribeSObjectContext(ctx context.Context, request *DescribeSObject) (*DescribeSObjectResponse, error) {
 	response := new(DescribeSObjectResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -16955,8 +16941,12 @@ func (service *Soap) DescribeSObject(request *DescribeSObject) (*DescribeSObject
 //   - UnexpectedErrorFault
 /* Describe multiple sObjects (upto 100) */
 func (service *Soap) DescribeSObjects(request *DescribeSObjects) (*DescribeSObjectsResponse, error) {
+	return service.DescribeSObjectsContext(context.Background(), request)
+}
+
+func (service *Soap) DescribeSObjectsContext(ctx context.Context, request *DescribeSObjects) (*DescribeSObjectsResponse, error) {
 	response := new(DescribeSObjectsResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -16969,8 +16959,12 @@ func (service *Soap) DescribeSObjects(request *DescribeSObjects) (*DescribeSObje
 //   - UnexpectedErrorFault
 /* Describe the Global state */
 func (service *Soap) DescribeGlobal(request *DescribeGlobal) (*DescribeGlobalResponse, error) {
+	return service.DescribeGlobalContext(context.Background(), request)
+}
+
+func (service *Soap) DescribeGlobalContext(ctx context.Context, request *DescribeGlobal) (*DescribeGlobalResponse, error) {
 	response := new(DescribeGlobalResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -16984,8 +16978,12 @@ func (service *Soap) DescribeGlobal(request *DescribeGlobal) (*DescribeGlobalRes
 //   - UnexpectedErrorFault
 /* Describe all the data category groups available for a given set of types */
 func (service *Soap) DescribeDataCategoryGroups(request *DescribeDataCategoryGroups) (*DescribeDataCategoryGroupsResponse, error) {
+	return service.DescribeDataCategoryGroupsContext(context.Background(), request)
+}
+
+func (service *Soap) DescribeDataCategoryGroupsContext(ctx context.Context, request *DescribeDataCategoryGroups) (*DescribeDataCategoryGroupsResponse, error) {
 	response := new(DescribeDataCategoryGroupsResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -16999,8 +16997,12 @@ func (service *Soap) DescribeDataCategoryGroups(request *DescribeDataCategoryGro
 //   - UnexpectedErrorFault
 /* Describe the data category group structures for a given set of pair of types and data category group name */
 func (service *Soap) DescribeDataCategoryGroupStructures(request *DescribeDataCategoryGroupStructures) (*DescribeDataCategoryGroupStructuresResponse, error) {
+	return service.DescribeDataCategoryGroupStructuresContext(context.Background(), request)
+}
+
+func (service *Soap) DescribeDataCategoryGroupStructuresContext(ctx context.Context, request *DescribeDataCategoryGroupStructures) (*DescribeDataCategoryGroupStructuresResponse, error) {
 	response := new(DescribeDataCategoryGroupStructuresResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17013,8 +17015,12 @@ func (service *Soap) DescribeDataCategoryGroupStructures(request *DescribeDataCa
 //   - UnexpectedErrorFault
 /* Describe your Data Category Mappings. */
 func (service *Soap) DescribeDataCategoryMappings(request *DescribeDataCategoryMappings) (*DescribeDataCategoryMappingsResponse, error) {
+	return service.DescribeDataCategoryMappingsContext(context.Background(), request)
+}
+
+func (service *Soap) DescribeDataCategoryMappingsContext(ctx context.Context, request *DescribeDataCategoryMappings) (*DescribeDataCategoryMappingsResponse, error) {
 	response := new(DescribeDataCategoryMappingsResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17027,8 +17033,12 @@ func (service *Soap) DescribeDataCategoryMappings(request *DescribeDataCategoryM
 //   - UnexpectedErrorFault
 /* Describes your Knowledge settings, such as if knowledgeEnabled is on or off, its default language and supported languages */
 func (service *Soap) DescribeKnowledgeSettings(request *DescribeKnowledgeSettings) (*DescribeKnowledgeSettingsResponse, error) {
+	return service.DescribeKnowledgeSettingsContext(context.Background(), request)
+}
+
+func (service *Soap) DescribeKnowledgeSettingsContext(ctx context.Context, request *DescribeKnowledgeSettings) (*DescribeKnowledgeSettingsResponse, error) {
 	response := new(DescribeKnowledgeSettingsResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17041,8 +17051,12 @@ func (service *Soap) DescribeKnowledgeSettings(request *DescribeKnowledgeSetting
 //   - UnexpectedErrorFault
 /* Describe the items in an AppMenu */
 func (service *Soap) DescribeAppMenu(request *DescribeAppMenu) (*DescribeAppMenuResponse, error) {
+	return service.DescribeAppMenuContext(context.Background(), request)
+}
+
+func (service *Soap) DescribeAppMenuContext(ctx context.Context, request *DescribeAppMenu) (*DescribeAppMenuResponse, error) {
 	response := new(DescribeAppMenuResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17055,8 +17069,12 @@ func (service *Soap) DescribeAppMenu(request *DescribeAppMenu) (*DescribeAppMenu
 //   - UnexpectedErrorFault
 /* Describe Gloal and Themes */
 func (service *Soap) DescribeGlobalTheme(request *DescribeGlobalTheme) (*DescribeGlobalThemeResponse, error) {
+	return service.DescribeGlobalThemeContext(context.Background(), request)
+}
+
+func (service *Soap) DescribeGlobalThemeContext(ctx context.Context, request *DescribeGlobalTheme) (*DescribeGlobalThemeResponse, error) {
 	response := new(DescribeGlobalThemeResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17069,8 +17087,12 @@ func (service *Soap) DescribeGlobalTheme(request *DescribeGlobalTheme) (*Describ
 //   - UnexpectedErrorFault
 /* Describe Themes */
 func (service *Soap) DescribeTheme(request *DescribeTheme) (*DescribeThemeResponse, error) {
+	return service.DescribeThemeContext(context.Background(), request)
+}
+
+func (service *Soap) DescribeThemeContext(ctx context.Context, request *DescribeTheme) (*DescribeThemeResponse, error) {
 	response := new(DescribeThemeResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17085,8 +17107,12 @@ func (service *Soap) DescribeTheme(request *DescribeTheme) (*DescribeThemeRespon
 //   - InvalidIdFault
 /* Describe the layout of the given sObject or the given actionable global page. */
 func (service *Soap) DescribeLayout(request *DescribeLayout) (*DescribeLayoutResponse, error) {
+	return service.DescribeLayoutContext(context.Background(), request)
+}
+
+func (service *Soap) DescribeLayoutContext(ctx context.Context, request *DescribeLayout) (*DescribeLayoutResponse, error) {
 	response := new(DescribeLayoutResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17099,8 +17125,12 @@ func (service *Soap) DescribeLayout(request *DescribeLayout) (*DescribeLayoutRes
 //   - UnexpectedErrorFault
 /* Describe the layout of the SoftPhone */
 func (service *Soap) DescribeSoftphoneLayout(request *DescribeSoftphoneLayout) (*DescribeSoftphoneLayoutResponse, error) {
+	return service.DescribeSoftphoneLayoutContext(context.Background(), request)
+}
+
+func (service *Soap) DescribeSoftphoneLayoutContext(ctx context.Context, request *DescribeSoftphoneLayout) (*DescribeSoftphoneLayoutResponse, error) {
 	response := new(DescribeSoftphoneLayoutResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17114,8 +17144,12 @@ func (service *Soap) DescribeSoftphoneLayout(request *DescribeSoftphoneLayout) (
 //   - UnexpectedErrorFault
 /* Describe the search view of an sObject */
 func (service *Soap) DescribeSearchLayouts(request *DescribeSearchLayouts) (*DescribeSearchLayoutsResponse, error) {
+	return service.DescribeSearchLayoutsContext(context.Background(), request)
+}
+
+func (service *Soap) DescribeSearchLayoutsContext(ctx context.Context, request *DescribeSearchLayouts) (*DescribeSearchLayoutsResponse, error) {
 	response := new(DescribeSearchLayoutsResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17125,8 +17159,12 @@ func (service *Soap) DescribeSearchLayouts(request *DescribeSearchLayouts) (*Des
 
 /* Describe a list of entity names that reflects the current user's searchable entities */
 func (service *Soap) DescribeSearchableEntities(request *DescribeSearchableEntities) (*DescribeSearchableEntitiesResponse, error) {
+	return service.DescribeSearchableEntitiesContext(context.Background(), request)
+}
+
+func (service *Soap) DescribeSearchableEntitiesContext(ctx context.Context, request *DescribeSearchableEntities) (*DescribeSearchableEntitiesResponse, error) {
 	response := new(DescribeSearchableEntitiesResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17136,8 +17174,12 @@ func (service *Soap) DescribeSearchableEntities(request *DescribeSearchableEntit
 
 /* Describe a list of objects representing the order and scope of objects on a users search result page */
 func (service *Soap) DescribeSearchScopeOrder(request *DescribeSearchScopeOrder) (*DescribeSearchScopeOrderResponse, error) {
+	return service.DescribeSearchScopeOrderContext(context.Background(), request)
+}
+
+func (service *Soap) DescribeSearchScopeOrderContext(ctx context.Context, request *DescribeSearchScopeOrder) (*DescribeSearchScopeOrderResponse, error) {
 	response := new(DescribeSearchScopeOrderResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17147,8 +17189,12 @@ func (service *Soap) DescribeSearchScopeOrder(request *DescribeSearchScopeOrder)
 
 /* Describe the compact layouts of the given sObject */
 func (service *Soap) DescribeCompactLayouts(request *DescribeCompactLayouts) (*DescribeCompactLayoutsResponse, error) {
+	return service.DescribeCompactLayoutsContext(context.Background(), request)
+}
+
+func (service *Soap) DescribeCompactLayoutsContext(ctx context.Context, request *DescribeCompactLayouts) (*DescribeCompactLayoutsResponse, error) {
 	response := new(DescribeCompactLayoutsResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17158,8 +17204,12 @@ func (service *Soap) DescribeCompactLayouts(request *DescribeCompactLayouts) (*D
 
 /* Describe the Path Assistants for the given sObject and optionally RecordTypes */
 func (service *Soap) DescribePathAssistants(request *DescribePathAssistants) (*DescribePathAssistantsResponse, error) {
+	return service.DescribePathAssistantsContext(context.Background(), request)
+}
+
+func (service *Soap) DescribePathAssistantsContext(ctx context.Context, request *DescribePathAssistants) (*DescribePathAssistantsResponse, error) {
 	response := new(DescribePathAssistantsResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17169,8 +17219,12 @@ func (service *Soap) DescribePathAssistants(request *DescribePathAssistants) (*D
 
 /* Describe the approval layouts of the given sObject */
 func (service *Soap) DescribeApprovalLayout(request *DescribeApprovalLayout) (*DescribeApprovalLayoutResponse, error) {
+	return service.DescribeApprovalLayoutContext(context.Background(), request)
+}
+
+func (service *Soap) DescribeApprovalLayoutContext(ctx context.Context, request *DescribeApprovalLayout) (*DescribeApprovalLayoutResponse, error) {
 	response := new(DescribeApprovalLayoutResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17184,8 +17238,12 @@ func (service *Soap) DescribeApprovalLayout(request *DescribeApprovalLayout) (*D
 //   - UnexpectedErrorFault
 /* Describe the ListViews as SOQL metadata for the generation of SOQL. */
 func (service *Soap) DescribeSoqlListViews(request *DescribeSoqlListViews) (*DescribeSoqlListViewsResponse, error) {
+	return service.DescribeSoqlListViewsContext(context.Background(), request)
+}
+
+func (service *Soap) DescribeSoqlListViewsContext(ctx context.Context, request *DescribeSoqlListViews) (*DescribeSoqlListViewsResponse, error) {
 	response := new(DescribeSoqlListViewsResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17195,8 +17253,12 @@ func (service *Soap) DescribeSoqlListViews(request *DescribeSoqlListViews) (*Des
 
 /* Execute the specified list view and return the presentation-ready results. */
 func (service *Soap) ExecuteListView(request *ExecuteListView) (*ExecuteListViewResponse, error) {
+	return service.ExecuteListViewContext(context.Background(), request)
+}
+
+func (service *Soap) ExecuteListViewContext(ctx context.Context, request *ExecuteListView) (*ExecuteListViewResponse, error) {
 	response := new(ExecuteListViewResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17210,8 +17272,12 @@ func (service *Soap) ExecuteListView(request *ExecuteListView) (*ExecuteListView
 //   - UnexpectedErrorFault
 /* Describe the ListViews of a SObject as SOQL metadata for the generation of SOQL. */
 func (service *Soap) DescribeSObjectListViews(request *DescribeSObjectListViews) (*DescribeSObjectListViewsResponse, error) {
+	return service.DescribeSObjectListViewsContext(context.Background(), request)
+}
+
+func (service *Soap) DescribeSObjectListViewsContext(ctx context.Context, request *DescribeSObjectListViews) (*DescribeSObjectListViewsResponse, error) {
 	response := new(DescribeSObjectListViewsResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17224,8 +17290,12 @@ func (service *Soap) DescribeSObjectListViews(request *DescribeSObjectListViews)
 //   - UnexpectedErrorFault
 /* Describe the tabs that appear on a users page */
 func (service *Soap) DescribeTabs(request *DescribeTabs) (*DescribeTabsResponse, error) {
+	return service.DescribeTabsContext(context.Background(), request)
+}
+
+func (service *Soap) DescribeTabsContext(ctx context.Context, request *DescribeTabs) (*DescribeTabsResponse, error) {
 	response := new(DescribeTabsResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17238,8 +17308,12 @@ func (service *Soap) DescribeTabs(request *DescribeTabs) (*DescribeTabsResponse,
 //   - UnexpectedErrorFault
 /* Describe all tabs available to a user */
 func (service *Soap) DescribeAllTabs(request *DescribeAllTabs) (*DescribeAllTabsResponse, error) {
+	return service.DescribeAllTabsContext(context.Background(), request)
+}
+
+func (service *Soap) DescribeAllTabsContext(ctx context.Context, request *DescribeAllTabs) (*DescribeAllTabsResponse, error) {
 	response := new(DescribeAllTabsResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17249,8 +17323,12 @@ func (service *Soap) DescribeAllTabs(request *DescribeAllTabs) (*DescribeAllTabs
 
 /* Describe the primary compact layouts for the sObjects requested */
 func (service *Soap) DescribePrimaryCompactLayouts(request *DescribePrimaryCompactLayouts) (*DescribePrimaryCompactLayoutsResponse, error) {
+	return service.DescribePrimaryCompactLayoutsContext(context.Background(), request)
+}
+
+func (service *Soap) DescribePrimaryCompactLayoutsContext(ctx context.Context, request *DescribePrimaryCompactLayouts) (*DescribePrimaryCompactLayoutsResponse, error) {
 	response := new(DescribePrimaryCompactLayoutsResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17266,8 +17344,12 @@ func (service *Soap) DescribePrimaryCompactLayouts(request *DescribePrimaryCompa
 //   - InvalidFieldFault
 /* Create a set of new sObjects */
 func (service *Soap) Create(request *Create) (*CreateResponse, error) {
+	return service.CreateContext(context.Background(), request)
+}
+
+func (service *Soap) CreateContext(ctx context.Context, request *Create) (*CreateResponse, error) {
 	response := new(CreateResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17283,8 +17365,12 @@ func (service *Soap) Create(request *Create) (*CreateResponse, error) {
 //   - InvalidFieldFault
 /* Update a set of sObjects */
 func (service *Soap) Update(request *Update) (*UpdateResponse, error) {
+	return service.UpdateContext(context.Background(), request)
+}
+
+func (service *Soap) UpdateContext(ctx context.Context, request *Update) (*UpdateResponse, error) {
 	response := new(UpdateResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17300,8 +17386,12 @@ func (service *Soap) Update(request *Update) (*UpdateResponse, error) {
 //   - InvalidFieldFault
 /* Update or insert a set of sObjects based on object id */
 func (service *Soap) Upsert(request *Upsert) (*UpsertResponse, error) {
+	return service.UpsertContext(context.Background(), request)
+}
+
+func (service *Soap) UpsertContext(ctx context.Context, request *Upsert) (*UpsertResponse, error) {
 	response := new(UpsertResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17317,8 +17407,12 @@ func (service *Soap) Upsert(request *Upsert) (*UpsertResponse, error) {
 //   - InvalidFieldFault
 /* Merge and update a set of sObjects based on object id */
 func (service *Soap) Merge(request *Merge) (*MergeResponse, error) {
+	return service.MergeContext(context.Background(), request)
+}
+
+func (service *Soap) MergeContext(ctx context.Context, request *Merge) (*MergeResponse, error) {
 	response := new(MergeResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17331,8 +17425,12 @@ func (service *Soap) Merge(request *Merge) (*MergeResponse, error) {
 //   - UnexpectedErrorFault
 /* Delete a set of sObjects */
 func (service *Soap) Delete(request *Delete) (*DeleteResponse, error) {
+	return service.DeleteContext(context.Background(), request)
+}
+
+func (service *Soap) DeleteContext(ctx context.Context, request *Delete) (*DeleteResponse, error) {
 	response := new(DeleteResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17345,8 +17443,12 @@ func (service *Soap) Delete(request *Delete) (*DeleteResponse, error) {
 //   - UnexpectedErrorFault
 /* Undelete a set of sObjects */
 func (service *Soap) Undelete(request *Undelete) (*UndeleteResponse, error) {
+	return service.UndeleteContext(context.Background(), request)
+}
+
+func (service *Soap) UndeleteContext(ctx context.Context, request *Undelete) (*UndeleteResponse, error) {
 	response := new(UndeleteResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17359,8 +17461,12 @@ func (service *Soap) Undelete(request *Undelete) (*UndeleteResponse, error) {
 //   - UnexpectedErrorFault
 /* Empty a set of sObjects from the recycle bin */
 func (service *Soap) EmptyRecycleBin(request *EmptyRecycleBin) (*EmptyRecycleBinResponse, error) {
+	return service.EmptyRecycleBinContext(context.Background(), request)
+}
+
+func (service *Soap) EmptyRecycleBinContext(ctx context.Context, request *EmptyRecycleBin) (*EmptyRecycleBinResponse, error) {
 	response := new(EmptyRecycleBinResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17377,8 +17483,12 @@ func (service *Soap) EmptyRecycleBin(request *EmptyRecycleBin) (*EmptyRecycleBin
 //   - InvalidIdFault
 /* Get a set of sObjects */
 func (service *Soap) Retrieve(request *Retrieve) (*RetrieveResponse, error) {
+	return service.RetrieveContext(context.Background(), request)
+}
+
+func (service *Soap) RetrieveContext(ctx context.Context, request *Retrieve) (*RetrieveResponse, error) {
 	response := new(RetrieveResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17392,8 +17502,12 @@ func (service *Soap) Retrieve(request *Retrieve) (*RetrieveResponse, error) {
 //   - InvalidIdFault
 /* Submit an entity to a workflow process or process a workitem */
 func (service *Soap) Process(request *Process) (*ProcessResponse, error) {
+	return service.ProcessContext(context.Background(), request)
+}
+
+func (service *Soap) ProcessContext(ctx context.Context, request *Process) (*ProcessResponse, error) {
 	response := new(ProcessResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17406,8 +17520,12 @@ func (service *Soap) Process(request *Process) (*ProcessResponse, error) {
 //   - UnexpectedErrorFault
 /* convert a set of leads */
 func (service *Soap) ConvertLead(request *ConvertLead) (*ConvertLeadResponse, error) {
+	return service.ConvertLeadContext(context.Background(), request)
+}
+
+func (service *Soap) ConvertLeadContext(ctx context.Context, request *ConvertLead) (*ConvertLeadResponse, error) {
 	response := new(ConvertLeadResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17420,8 +17538,12 @@ func (service *Soap) ConvertLead(request *ConvertLead) (*ConvertLeadResponse, er
 //   - UnexpectedErrorFault
 /* Logout the current user, invalidating the current session. */
 func (service *Soap) Logout(request *Logout) (*LogoutResponse, error) {
+	return service.LogoutContext(context.Background(), request)
+}
+
+func (service *Soap) LogoutContext(ctx context.Context, request *Logout) (*LogoutResponse, error) {
 	response := new(LogoutResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17434,8 +17556,12 @@ func (service *Soap) Logout(request *Logout) (*LogoutResponse, error) {
 //   - UnexpectedErrorFault
 /* Logs out and invalidates session ids */
 func (service *Soap) InvalidateSessions(request *InvalidateSessions) (*InvalidateSessionsResponse, error) {
+	return service.InvalidateSessionsContext(context.Background(), request)
+}
+
+func (service *Soap) InvalidateSessionsContext(ctx context.Context, request *InvalidateSessions) (*InvalidateSessionsResponse, error) {
 	response := new(InvalidateSessionsResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17449,8 +17575,12 @@ func (service *Soap) InvalidateSessions(request *InvalidateSessions) (*Invalidat
 //   - UnexpectedErrorFault
 /* Get the IDs for deleted sObjects */
 func (service *Soap) GetDeleted(request *GetDeleted) (*GetDeletedResponse, error) {
+	return service.GetDeletedContext(context.Background(), request)
+}
+
+func (service *Soap) GetDeletedContext(ctx context.Context, request *GetDeleted) (*GetDeletedResponse, error) {
 	response := new(GetDeletedResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17464,8 +17594,12 @@ func (service *Soap) GetDeleted(request *GetDeleted) (*GetDeletedResponse, error
 //   - UnexpectedErrorFault
 /* Get the IDs for updated sObjects */
 func (service *Soap) GetUpdated(request *GetUpdated) (*GetUpdatedResponse, error) {
+	return service.GetUpdatedContext(context.Background(), request)
+}
+
+func (service *Soap) GetUpdatedContext(ctx context.Context, request *GetUpdated) (*GetUpdatedResponse, error) {
 	response := new(GetUpdatedResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17483,8 +17617,12 @@ func (service *Soap) GetUpdated(request *GetUpdated) (*GetUpdatedResponse, error
 //   - InvalidQueryLocatorFault
 /* Create a Query Cursor */
 func (service *Soap) Query(request *Query) (*QueryResponse, error) {
+	return service.QueryContext(context.Background(), request)
+}
+
+func (service *Soap) QueryContext(ctx context.Context, request *Query) (*QueryResponse, error) {
 	response := new(QueryResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17502,8 +17640,12 @@ func (service *Soap) Query(request *Query) (*QueryResponse, error) {
 //   - InvalidQueryLocatorFault
 /* Create a Query Cursor, including deleted sObjects */
 func (service *Soap) QueryAll(request *QueryAll) (*QueryAllResponse, error) {
+	return service.QueryAllContext(context.Background(), request)
+}
+
+func (service *Soap) QueryAllContext(ctx context.Context, request *QueryAll) (*QueryAllResponse, error) {
 	response := new(QueryAllResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17519,8 +17661,12 @@ func (service *Soap) QueryAll(request *QueryAll) (*QueryAllResponse, error) {
 //   - MalformedQueryFault
 /* Gets the next batch of sObjects from a query */
 func (service *Soap) QueryMore(request *QueryMore) (*QueryMoreResponse, error) {
+	return service.QueryMoreContext(context.Background(), request)
+}
+
+func (service *Soap) QueryMoreContext(ctx context.Context, request *QueryMore) (*QueryMoreResponse, error) {
 	response := new(QueryMoreResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17536,8 +17682,12 @@ func (service *Soap) QueryMore(request *QueryMore) (*QueryMoreResponse, error) {
 //   - UnexpectedErrorFault
 /* Search for sObjects */
 func (service *Soap) Search(request *Search) (*SearchResponse, error) {
+	return service.SearchContext(context.Background(), request)
+}
+
+func (service *Soap) SearchContext(ctx context.Context, request *Search) (*SearchResponse, error) {
 	response := new(SearchResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17550,8 +17700,12 @@ func (service *Soap) Search(request *Search) (*SearchResponse, error) {
 //   - UnexpectedErrorFault
 /* Gets server timestamp */
 func (service *Soap) GetServerTimestamp(request *GetServerTimestamp) (*GetServerTimestampResponse, error) {
+	return service.GetServerTimestampContext(context.Background(), request)
+}
+
+func (service *Soap) GetServerTimestampContext(ctx context.Context, request *GetServerTimestamp) (*GetServerTimestampResponse, error) {
 	response := new(GetServerTimestampResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17566,8 +17720,12 @@ func (service *Soap) GetServerTimestamp(request *GetServerTimestamp) (*GetServer
 //   - UnexpectedErrorFault
 /* Set a user's password */
 func (service *Soap) SetPassword(request *SetPassword) (*SetPasswordResponse, error) {
+	return service.SetPasswordContext(context.Background(), request)
+}
+
+func (service *Soap) SetPasswordContext(ctx context.Context, request *SetPassword) (*SetPasswordResponse, error) {
 	response := new(SetPasswordResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17582,8 +17740,12 @@ func (service *Soap) SetPassword(request *SetPassword) (*SetPasswordResponse, er
 //   - UnexpectedErrorFault
 /* Change the current user's password */
 func (service *Soap) ChangeOwnPassword(request *ChangeOwnPassword) (*ChangeOwnPasswordResponse, error) {
+	return service.ChangeOwnPasswordContext(context.Background(), request)
+}
+
+func (service *Soap) ChangeOwnPasswordContext(ctx context.Context, request *ChangeOwnPassword) (*ChangeOwnPasswordResponse, error) {
 	response := new(ChangeOwnPasswordResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17597,8 +17759,12 @@ func (service *Soap) ChangeOwnPassword(request *ChangeOwnPassword) (*ChangeOwnPa
 //   - UnexpectedErrorFault
 /* Reset a user's password */
 func (service *Soap) ResetPassword(request *ResetPassword) (*ResetPasswordResponse, error) {
+	return service.ResetPasswordContext(context.Background(), request)
+}
+
+func (service *Soap) ResetPasswordContext(ctx context.Context, request *ResetPassword) (*ResetPasswordResponse, error) {
 	response := new(ResetPasswordResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17611,8 +17777,12 @@ func (service *Soap) ResetPassword(request *ResetPassword) (*ResetPasswordRespon
 //   - UnexpectedErrorFault
 /* Returns standard information relevant to the current user */
 func (service *Soap) GetUserInfo(request *GetUserInfo) (*GetUserInfoResponse, error) {
+	return service.GetUserInfoContext(context.Background(), request)
+}
+
+func (service *Soap) GetUserInfoContext(ctx context.Context, request *GetUserInfo) (*GetUserInfoResponse, error) {
 	response := new(GetUserInfoResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17625,8 +17795,12 @@ func (service *Soap) GetUserInfo(request *GetUserInfo) (*GetUserInfoResponse, er
 //   - UnexpectedErrorFault
 /* Delete a set of sObjects by example. The passed SOBject is a template for the object to delete */
 func (service *Soap) DeleteByExample(request *DeleteByExample) (*DeleteByExampleResponse, error) {
+	return service.DeleteByExampleContext(context.Background(), request)
+}
+
+func (service *Soap) DeleteByExampleContext(ctx context.Context, request *DeleteByExample) (*DeleteByExampleResponse, error) {
 	response := new(DeleteByExampleResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17639,8 +17813,12 @@ func (service *Soap) DeleteByExample(request *DeleteByExample) (*DeleteByExample
 //   - UnexpectedErrorFault
 /* Send existing draft EmailMessage */
 func (service *Soap) SendEmailMessage(request *SendEmailMessage) (*SendEmailMessageResponse, error) {
+	return service.SendEmailMessageContext(context.Background(), request)
+}
+
+func (service *Soap) SendEmailMessageContext(ctx context.Context, request *SendEmailMessage) (*SendEmailMessageResponse, error) {
 	response := new(SendEmailMessageResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17652,8 +17830,12 @@ func (service *Soap) SendEmailMessage(request *SendEmailMessage) (*SendEmailMess
 //   - UnexpectedErrorFault
 /* Send outbound email */
 func (service *Soap) SendEmail(request *SendEmail) (*SendEmailResponse, error) {
+	return service.SendEmailContext(context.Background(), request)
+}
+
+func (service *Soap) SendEmailContext(ctx context.Context, request *SendEmail) (*SendEmailResponse, error) {
 	response := new(SendEmailResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17666,8 +17848,12 @@ func (service *Soap) SendEmail(request *SendEmail) (*SendEmailResponse, error) {
 //   - UnexpectedErrorFault
 /* Perform a template merge on one or more blocks of text. */
 func (service *Soap) RenderEmailTemplate(request *RenderEmailTemplate) (*RenderEmailTemplateResponse, error) {
+	return service.RenderEmailTemplateContext(context.Background(), request)
+}
+
+func (service *Soap) RenderEmailTemplateContext(ctx context.Context, request *RenderEmailTemplate) (*RenderEmailTemplateResponse, error) {
 	response := new(RenderEmailTemplateResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17680,8 +17866,12 @@ func (service *Soap) RenderEmailTemplate(request *RenderEmailTemplate) (*RenderE
 //   - UnexpectedErrorFault
 /* Perform a template merge using an email template stored in the database. */
 func (service *Soap) RenderStoredEmailTemplate(request *RenderStoredEmailTemplate) (*RenderStoredEmailTemplateResponse, error) {
+	return service.RenderStoredEmailTemplateContext(context.Background(), request)
+}
+
+func (service *Soap) RenderStoredEmailTemplateContext(ctx context.Context, request *RenderStoredEmailTemplate) (*RenderStoredEmailTemplateResponse, error) {
 	response := new(RenderStoredEmailTemplateResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17691,8 +17881,12 @@ func (service *Soap) RenderStoredEmailTemplate(request *RenderStoredEmailTemplat
 
 /* Perform a series of predefined actions such as quick create or log a task */
 func (service *Soap) PerformQuickActions(request *PerformQuickActions) (*PerformQuickActionsResponse, error) {
+	return service.PerformQuickActionsContext(context.Background(), request)
+}
+
+func (service *Soap) PerformQuickActionsContext(ctx context.Context, request *PerformQuickActions) (*PerformQuickActionsResponse, error) {
 	response := new(PerformQuickActionsResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17702,8 +17896,12 @@ func (service *Soap) PerformQuickActions(request *PerformQuickActions) (*Perform
 
 /* Describe the details of a series of quick actions */
 func (service *Soap) DescribeQuickActions(request *DescribeQuickActions) (*DescribeQuickActionsResponse, error) {
+	return service.DescribeQuickActionsContext(context.Background(), request)
+}
+
+func (service *Soap) DescribeQuickActionsContext(ctx context.Context, request *DescribeQuickActions) (*DescribeQuickActionsResponse, error) {
 	response := new(DescribeQuickActionsResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17713,8 +17911,12 @@ func (service *Soap) DescribeQuickActions(request *DescribeQuickActions) (*Descr
 
 /* Describe the details of a series of quick actions in context of requested recordType id for Update actions */
 func (service *Soap) DescribeQuickActionsForRecordType(request *DescribeQuickActionsForRecordType) (*DescribeQuickActionsForRecordTypeResponse, error) {
+	return service.DescribeQuickActionsForRecordTypeContext(context.Background(), request)
+}
+
+func (service *Soap) DescribeQuickActionsForRecordTypeContext(ctx context.Context, request *DescribeQuickActionsForRecordType) (*DescribeQuickActionsForRecordTypeResponse, error) {
 	response := new(DescribeQuickActionsForRecordTypeResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17724,8 +17926,12 @@ func (service *Soap) DescribeQuickActionsForRecordType(request *DescribeQuickAct
 
 /* Describe the details of a series of quick actions available for the given contextType */
 func (service *Soap) DescribeAvailableQuickActions(request *DescribeAvailableQuickActions) (*DescribeAvailableQuickActionsResponse, error) {
+	return service.DescribeAvailableQuickActionsContext(context.Background(), request)
+}
+
+func (service *Soap) DescribeAvailableQuickActionsContext(ctx context.Context, request *DescribeAvailableQuickActions) (*DescribeAvailableQuickActionsResponse, error) {
 	response := new(DescribeAvailableQuickActionsResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17735,8 +17941,12 @@ func (service *Soap) DescribeAvailableQuickActions(request *DescribeAvailableQui
 
 /* Retrieve the template sobjects, if appropriate, for the given quick action names in a given context */
 func (service *Soap) RetrieveQuickActionTemplates(request *RetrieveQuickActionTemplates) (*RetrieveQuickActionTemplatesResponse, error) {
+	return service.RetrieveQuickActionTemplatesContext(context.Background(), request)
+}
+
+func (service *Soap) RetrieveQuickActionTemplatesContext(ctx context.Context, request *RetrieveQuickActionTemplates) (*RetrieveQuickActionTemplatesResponse, error) {
 	response := new(RetrieveQuickActionTemplatesResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17746,8 +17956,12 @@ func (service *Soap) RetrieveQuickActionTemplates(request *RetrieveQuickActionTe
 
 /* Retrieve the template sobjects, if appropriate, for the given quick action names in a given contexts when used a mass quick action */
 func (service *Soap) RetrieveMassQuickActionTemplates(request *RetrieveMassQuickActionTemplates) (*RetrieveMassQuickActionTemplatesResponse, error) {
+	return service.RetrieveMassQuickActionTemplatesContext(context.Background(), request)
+}
+
+func (service *Soap) RetrieveMassQuickActionTemplatesContext(ctx context.Context, request *RetrieveMassQuickActionTemplates) (*RetrieveMassQuickActionTemplatesResponse, error) {
 	response := new(RetrieveMassQuickActionTemplatesResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17757,8 +17971,12 @@ func (service *Soap) RetrieveMassQuickActionTemplates(request *RetrieveMassQuick
 
 /* Describe visualforce for an org */
 func (service *Soap) DescribeVisualForce(request *DescribeVisualForce) (*DescribeVisualForceResponse, error) {
+	return service.DescribeVisualForceContext(context.Background(), request)
+}
+
+func (service *Soap) DescribeVisualForceContext(ctx context.Context, request *DescribeVisualForce) (*DescribeVisualForceResponse, error) {
 	response := new(DescribeVisualForceResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17773,8 +17991,12 @@ func (service *Soap) DescribeVisualForce(request *DescribeVisualForce) (*Describ
 //   - InvalidFieldFault
 /* Find duplicates for a set of sObjects */
 func (service *Soap) FindDuplicates(request *FindDuplicates) (*FindDuplicatesResponse, error) {
+	return service.FindDuplicatesContext(context.Background(), request)
+}
+
+func (service *Soap) FindDuplicatesContext(ctx context.Context, request *FindDuplicates) (*FindDuplicatesResponse, error) {
 	response := new(FindDuplicatesResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17788,8 +18010,12 @@ func (service *Soap) FindDuplicates(request *FindDuplicates) (*FindDuplicatesRes
 //   - UnexpectedErrorFault
 /* Find duplicates for a set of ids */
 func (service *Soap) FindDuplicatesByIds(request *FindDuplicatesByIds) (*FindDuplicatesByIdsResponse, error) {
+	return service.FindDuplicatesByIdsContext(context.Background(), request)
+}
+
+func (service *Soap) FindDuplicatesByIdsContext(ctx context.Context, request *FindDuplicatesByIds) (*FindDuplicatesByIdsResponse, error) {
 	response := new(FindDuplicatesByIdsResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17799,8 +18025,12 @@ func (service *Soap) FindDuplicatesByIds(request *FindDuplicatesByIds) (*FindDup
 
 /* Return the renameable nouns from the server for use in presentation using the salesforce grammar engine */
 func (service *Soap) DescribeNouns(request *DescribeNouns) (*DescribeNounsResponse, error) {
+	return service.DescribeNounsContext(context.Background(), request)
+}
+
+func (service *Soap) DescribeNounsContext(ctx context.Context, request *DescribeNouns) (*DescribeNounsResponse, error) {
 	response := new(DescribeNounsResponse)
-	err := service.client.Call("", request, response)
+	err := service.client.CallContext(ctx, "", request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -17817,13 +18047,14 @@ func dialTimeout(network, addr string) (net.Conn, error) {
 type SOAPEnvelope struct {
 	XMLName xml.Name `xml:"http://schemas.xmlsoap.org/soap/envelope/ Envelope"`
 
-	Body SOAPBody
+	Header *SOAPHeader `xml:",omitempty"`
+	Body   SOAPBody
 }
 
 type SOAPHeader struct {
 	XMLName xml.Name `xml:"http://schemas.xmlsoap.org/soap/envelope/ Header"`
 
-	Header interface{}
+	Headers []interface{}
 }
 
 type SOAPBody struct {
@@ -17836,10 +18067,10 @@ type SOAPBody struct {
 type SOAPFault struct {
 	XMLName xml.Name `xml:"http://schemas.xmlsoap.org/soap/envelope/ Fault"`
 
-	Code   string `xml:"faultcode,omitempty"`
-	String string `xml:"faultstring,omitempty"`
-	Actor  string `xml:"faultactor,omitempty"`
-	Detail string `xml:"detail,omitempty"`
+	Code   string           `xml:"faultcode,omitempty"`
+	String string           `xml:"faultstring,omitempty"`
+	Actor  string           `xml:"faultactor,omitempty"`
+	Detail *SOAPFaultDetail `xml:"detail,omitempty"`
 }
 
 type BasicAuth struct {
@@ -17848,9 +18079,13 @@ type BasicAuth struct {
 }
 
 type SOAPClient struct {
-	url  string
-	tls  bool
-	auth *BasicAuth
+	url         string
+	tls         bool
+	auth        *BasicAuth
+	headers     []interface{}
+	httpClient  *http.Client
+	retryPolicy httpUtil.RetryPolicy
+	compression bool
 }
 
 func (b *SOAPBody) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
@@ -17915,9 +18150,40 @@ func NewSOAPClient(url string, tls bool, auth *BasicAuth) *SOAPClient {
 	}
 }
 
+// client returns the injected HTTP client, the shared default one, or with tls a client
+// skipping the certificate verification
+func (s *SOAPClient) client() *http.Client {
+	if s.httpClient != nil {
+		return s.httpClient
+	}
+	if !s.tls {
+		return httpUtil.DefaultClient()
+	}
+	tr := &http.Transport{
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: s.tls,
+		},
+		Dial: dialTimeout,
+	}
+	return &http.Client{Transport: httpUtil.NewTracingTransport(tr)}
+}
+
 func (s *SOAPClient) Call(soapAction string, request, response interface{}) error {
-	envelope := SOAPEnvelope{
-	//Header:        SoapHeader{},
+	return s.CallContext(context.Background(), soapAction, request, response)
+}
+
+// CallContext sends the request and decodes the response, the call is aborted when ctx is done
+func (s *SOAPClient) CallContext(ctx context.Context, soapAction string, request, response interface{}) error {
+	return s.CallStreamContext(ctx, soapAction, request, response, nil)
+}
+
+// CallStreamContext is CallContext handing every records element of the response to onRecord
+// while the body is read, instead of collecting them in response. When onRecord is nil the
+// response is decoded as by CallContext. A call failing after records were handed is not retried.
+func (s *SOAPClient) CallStreamContext(ctx context.Context, soapAction string, request, response interface{}, onRecord RecordHandler) error {
+	envelope := SOAPEnvelope{}
+	if len(s.headers) > 0 {
+		envelope.Header = &SOAPHeader{Headers: s.headers}
 	}
 
 	envelope.Body.Content = request
@@ -17934,9 +18200,24 @@ func (s *SOAPClient) Call(soapAction string, request, response interface{}) erro
 		return err
 	}
 
-	log.Println(buffer.String())
+	envelopeBytes := buffer.Bytes()
+	if s.compression {
+		compressedEnvelope, err := gzipBytes(envelopeBytes)
+		if err != nil {
+			return err
+		}
+		envelopeBytes = compressedEnvelope
+	}
+
+	// the envelope is kept to be sent again when the policy retries the call
+	operation := "SOAP " + reflect.TypeOf(request).Elem().Name()
+	return s.retryPolicy.Do(ctx, operation, func() error {
+		return s.send(ctx, operation, soapAction, envelopeBytes, response, onRecord)
+	})
+}
 
-	req, err := http.NewRequest("POST", s.url, buffer)
+func (s *SOAPClient) send(ctx context.Context, operation string, soapAction string, envelope []byte, response interface{}, onRecord RecordHandler) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", s.url, bytes.NewReader(envelope))
 	if err != nil {
 		return err
 	}
@@ -17950,36 +18231,44 @@ func (s *SOAPClient) Call(soapAction string, request, response interface{}) erro
 	}
 
 	req.Header.Set("User-Agent", "gowsdl/0.1")
-	req.Close = true
-
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: s.tls,
-		},
-		Dial: dialTimeout,
+	if s.compression {
+		req.Header.Set("Content-Encoding", "gzip")
+		req.Header.Set("Accept-Encoding", "gzip")
 	}
 
-	client := &http.Client{Transport: tr}
-	res, err := client.Do(req)
+	// the bodies carry the credentials and the session id, they are only logged redacted by the trace
+	res, err := s.client().Do(req)
 	if err != nil {
 		return err
 	}
 	defer res.Body.Close()
 
-	rawbody, err := ioutil.ReadAll(res.Body)
+	body, err := decodedBody(res)
 	if err != nil {
 		return err
 	}
-	if len(rawbody) == 0 {
-		log.Println("empty response")
-		return nil
-	}
 
-	log.Println(string(rawbody))
+	// the envelope is decoded while it is read, the body is never held in memory as a whole
 	respEnvelope := new(SOAPEnvelope)
 	respEnvelope.Body = SOAPBody{Content: response}
-	err = xml.Unmarshal(rawbody, respEnvelope)
+	stream := newResponseStream(body, onRecord)
+	err = stream.decoder.Decode(respEnvelope)
+	if err == io.EOF && stream.records == 0 {
+		if res.StatusCode >= http.StatusBadRequest {
+			return &httpUtil.StatusError{Operation: operation, StatusCode: res.StatusCode, Status: res.Status}
+		}
+		log.Println("empty response")
+		return nil
+	}
+	if err != nil && stream.records > 0 {
+		// the records already handed can't be taken back, the call must not be attempted again
+		return httpUtil.MarkPermanent(fmt.Errorf("%s interrupted after %d records: %w", operation, stream.records, err))
+	}
 	if err != nil {
+		// ie. an HTML error page from a proxy or during a maintenance
+		if res.StatusCode >= http.StatusBadRequest {
+			return &httpUtil.StatusError{Operation: operation, StatusCode: res.StatusCode, Status: res.Status}
+		}
 		return err
 	}
 
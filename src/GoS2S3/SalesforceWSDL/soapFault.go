@@ -0,0 +1,100 @@
+package SalesforceWSDL
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// SalesforceFault is implemented by every fault type Salesforce sends in the detail of a SOAP fault,
+// ie. errors.As(err, &fault) with a var fault SalesforceFault gives access to the exception code
+type SalesforceFault interface {
+	error
+	ApiFaultDetail() *ApiFault
+}
+
+// faultTypes builds the typed fault matching the element name found in the fault detail
+var faultTypes = map[string]func() SalesforceFault{
+	"ApiFault":                 func() SalesforceFault { return &ApiFault{} },
+	"ApiQueryFault":            func() SalesforceFault { return &ApiQueryFault{ApiFault: &ApiFault{}} },
+	"LoginFault":               func() SalesforceFault { return &LoginFault{ApiFault: &ApiFault{}} },
+	"InvalidQueryLocatorFault": func() SalesforceFault { return &InvalidQueryLocatorFault{ApiFault: &ApiFault{}} },
+	"InvalidNewPasswordFault":  func() SalesforceFault { return &InvalidNewPasswordFault{ApiFault: &ApiFault{}} },
+	"InvalidOldPasswordFault":  func() SalesforceFault { return &InvalidOldPasswordFault{ApiFault: &ApiFault{}} },
+	"InvalidIdFault":           func() SalesforceFault { return &InvalidIdFault{ApiFault: &ApiFault{}} },
+	"UnexpectedErrorFault":     func() SalesforceFault { return &UnexpectedErrorFault{ApiFault: &ApiFault{}} },
+	"InvalidFieldFault": func() SalesforceFault {
+		return &InvalidFieldFault{ApiQueryFault: &ApiQueryFault{ApiFault: &ApiFault{}}}
+	},
+	"InvalidSObjectFault": func() SalesforceFault {
+		return &InvalidSObjectFault{ApiQueryFault: &ApiQueryFault{ApiFault: &ApiFault{}}}
+	},
+	"MalformedQueryFault": func() SalesforceFault {
+		return &MalformedQueryFault{ApiQueryFault: &ApiQueryFault{ApiFault: &ApiFault{}}}
+	},
+	"MalformedSearchFault": func() SalesforceFault {
+		return &MalformedSearchFault{ApiQueryFault: &ApiQueryFault{ApiFault: &ApiFault{}}}
+	},
+}
+
+// SOAPFaultDetail is the detail element of a SOAP fault, Fault holds the typed fault
+// found in it or nil when the detail is missing or of an unknown type
+type SOAPFaultDetail struct {
+	Fault SalesforceFault
+}
+
+func (detail *SOAPFaultDetail) UnmarshalXML(decoder *xml.Decoder, start xml.StartElement) error {
+	for {
+		token, tokenError := decoder.Token()
+		if tokenError != nil {
+			return tokenError
+		}
+		switch element := token.(type) {
+		case xml.StartElement:
+			newFault, known := faultTypes[element.Name.Local]
+			if !known || detail.Fault != nil {
+				if skipError := decoder.Skip(); skipError != nil {
+					return skipError
+				}
+				continue
+			}
+			fault := newFault()
+			if decodeError := decoder.DecodeElement(fault, &element); decodeError != nil {
+				return decodeError
+			}
+			detail.Fault = fault
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// Unwrap returns the typed fault of the detail, so that callers can match it with errors.As
+func (f *SOAPFault) Unwrap() error {
+	if f.Detail == nil || f.Detail.Fault == nil {
+		return nil
+	}
+	return f.Detail.Fault
+}
+
+func (fault *ApiFault) Error() string {
+	return fmt.Sprintf("%s: %s", fault.Code(), fault.ExceptionMessage)
+}
+
+// Code returns the exception code, ie. "INVALID_LOGIN" or "INVALID_TYPE"
+func (fault *ApiFault) Code() string {
+	if fault.ExceptionCode == nil {
+		return ""
+	}
+	return string(*fault.ExceptionCode)
+}
+
+func (fault *ApiFault) ApiFaultDetail() *ApiFault {
+	return fault
+}
+
+func (fault *ApiQueryFault) Error() string {
+	if fault.Row == 0 && fault.Column == 0 {
+		return fault.ApiFault.Error()
+	}
+	return fmt.Sprintf("%s (row %d, column %d)", fault.ApiFault.Error(), fault.Row, fault.Column)
+}
@@ -0,0 +1,70 @@
+package SalesforceWSDL
+
+import (
+	"encoding/xml"
+	"reflect"
+)
+
+// CallOptions identifies the client making the calls (ie. a partner application), the bundled
+// WSDL predates it so it is declared here
+type CallOptions struct {
+	XMLName xml.Name `xml:"urn:enterprise.soap.sforce.com CallOptions"`
+
+	Client string `xml:"client,omitempty"`
+}
+
+// SetSession points the client to the server URL returned by the login and makes every
+// following call carry its session id
+func (service *Soap) SetSession(loginResult *LoginResult) {
+	service.client.url = loginResult.ServerUrl
+	service.SetSessionHeader(loginResult.SessionId)
+}
+
+// SetSessionHeader makes every following call carry a SessionHeader with sessionId,
+// required by every operation other than Login
+func (service *Soap) SetSessionHeader(sessionId string) {
+	service.setHeader(&SessionHeader{SessionId: sessionId})
+}
+
+// SetQueryOptions sets the number of records returned by each query/queryMore call (200 to 2000)
+func (service *Soap) SetQueryOptions(batchSize int32) {
+	service.setHeader(&QueryOptions{BatchSize: batchSize})
+}
+
+// SetCallOptions sets the client identifier sent with every call
+func (service *Soap) SetCallOptions(client string) {
+	service.setHeader(&CallOptions{Client: client})
+}
+
+// SetAllOrNoneHeader makes create, update, upsert and delete roll back every record when one fails
+func (service *Soap) SetAllOrNoneHeader(allOrNone bool) {
+	service.setHeader(&AllOrNoneHeader{AllOrNone: allOrNone})
+}
+
+// SetMruHeader makes the calls update the most recently used items list of the user
+func (service *Soap) SetMruHeader(updateMru bool) {
+	service.setHeader(&MruHeader{UpdateMru: updateMru})
+}
+
+// ClearHeader stops sending the header of the same type as header, ie. ClearHeader(&QueryOptions{})
+func (service *Soap) ClearHeader(header interface{}) {
+	headerType := reflect.TypeOf(header)
+	for index, existingHeader := range service.client.headers {
+		if reflect.TypeOf(existingHeader) == headerType {
+			service.client.headers = append(service.client.headers[:index], service.client.headers[index+1:]...)
+			return
+		}
+	}
+}
+
+// setHeader adds header to the ones sent with every call, replacing the previous one of the same type
+func (service *Soap) setHeader(header interface{}) {
+	headerType := reflect.TypeOf(header)
+	for index, existingHeader := range service.client.headers {
+		if reflect.TypeOf(existingHeader) == headerType {
+			service.client.headers[index] = header
+			return
+		}
+	}
+	service.client.headers = append(service.client.headers, header)
+}
@@ -0,0 +1,66 @@
+package SalesforceWSDL
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// getUserInfoResponse as returned by the enterprise API
+const getUserInfoResponse = `<?xml version="1.0" encoding="UTF-8"?>
+<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/" xmlns="urn:enterprise.soap.sforce.com" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+<soapenv:Body>
+<getUserInfoResponse>
+<result>
+<accessibilityMode>false</accessibilityMode>
+<currencySymbol>€</currencySymbol>
+<orgAttachmentFileSizeLimit>5242880</orgAttachmentFileSizeLimit>
+<orgDefaultCurrencyIsoCode>EUR</orgDefaultCurrencyIsoCode>
+<orgDisallowHtmlAttachments>false</orgDisallowHtmlAttachments>
+<orgHasPersonAccounts>false</orgHasPersonAccounts>
+<organizationId>00D5g000004ABCDEAA</organizationId>
+<organizationMultiCurrency>false</organizationMultiCurrency>
+<organizationName>Acme</organizationName>
+<profileId>00e5g000001ABCDAAA</profileId>
+<roleId xsi:nil="true"/>
+<sessionSecondsValid>7200</sessionSecondsValid>
+<userDefaultCurrencyIsoCode xsi:nil="true"/>
+<userEmail>backup@acme.com</userEmail>
+<userFullName>Backup User</userFullName>
+<userId>0055g00000ABCDEAA2</userId>
+<userLanguage>en_US</userLanguage>
+<userLocale>en_US</userLocale>
+<userName>backup@acme.com</userName>
+<userTimeZone>Europe/Paris</userTimeZone>
+<userType>Standard</userType>
+<userUiSkin>Theme3</userUiSkin>
+</result>
+</getUserInfoResponse>
+</soapenv:Body>
+</soapenv:Envelope>`
+
+func TestGetUserInfoDecoding(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "text/xml; charset=utf-8")
+		writer.Write([]byte(getUserInfoResponse))
+	}))
+	defer server.Close()
+
+	response, callError := NewSoap(server.URL, false, &BasicAuth{}).GetUserInfoContext(context.Background(), &GetUserInfo{})
+	if callError != nil {
+		t.Fatalf("getUserInfo: %v", callError)
+	}
+	if response.Result == nil {
+		t.Fatal("getUserInfo returned no result")
+	}
+	if response.Result.OrganizationId == nil || *response.Result.OrganizationId != "00D5g000004ABCDEAA" {
+		t.Errorf("OrganizationId = %v, want 00D5g000004ABCDEAA", response.Result.OrganizationId)
+	}
+	if response.Result.UserName != "backup@acme.com" {
+		t.Errorf("UserName = %q, want backup@acme.com", response.Result.UserName)
+	}
+	if response.Result.SessionSecondsValid != 7200 {
+		t.Errorf("SessionSecondsValid = %d, want 7200", response.Result.SessionSecondsValid)
+	}
+}
@@ -0,0 +1,87 @@
+package SalesforceWSDL
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+)
+
+// namespace of the records elements of the query results
+const enterpriseNamespace = "urn:enterprise.soap.sforce.com"
+
+// RecordHandler receives each records element of a streamed query result with the decoder
+// positioned right after its start. It must consume the element up to its end, ie. with
+// decoder.DecodeElement(&record, &start) or decoder.Skip().
+type RecordHandler func(decoder *xml.Decoder, start xml.StartElement) error
+
+// responseStream decodes a response envelope while it is read from the body,
+// handing the records elements to onRecord so that they are never collected
+type responseStream struct {
+	decoder  *xml.Decoder
+	body     *xml.Decoder
+	onRecord RecordHandler
+	records  int
+}
+
+func newResponseStream(body io.Reader, onRecord RecordHandler) *responseStream {
+	stream := &responseStream{body: xml.NewDecoder(body), onRecord: onRecord}
+	if onRecord == nil {
+		stream.decoder = stream.body
+	} else {
+		stream.decoder = xml.NewTokenDecoder(stream)
+	}
+	return stream
+}
+
+// Token implements xml.TokenReader for the envelope decoder, the records elements
+// are consumed by the handler on the body decoder and never reach the envelope
+func (stream *responseStream) Token() (xml.Token, error) {
+	for {
+		token, err := stream.body.Token()
+		if err != nil {
+			return token, err
+		}
+		start, isStart := token.(xml.StartElement)
+		if !isStart || start.Name.Space != enterpriseNamespace || start.Name.Local != "records" {
+			return token, nil
+		}
+		stream.records++
+		if err = stream.onRecord(stream.body, start); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// QueryStreamContext runs a query handing each record to onRecord as it is read, the result
+// of the response has no records but tells if the query is done and where to continue it
+func (service *Soap) QueryStreamContext(ctx context.Context, request *Query, onRecord RecordHandler) (*QueryResponse, error) {
+	response := new(QueryResponse)
+	err := service.client.CallStreamContext(ctx, "", request, response, onRecord)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// QueryAllStreamContext is QueryStreamContext including the deleted and archived records
+func (service *Soap) QueryAllStreamContext(ctx context.Context, request *QueryAll, onRecord RecordHandler) (*QueryAllResponse, error) {
+	response := new(QueryAllResponse)
+	err := service.client.CallStreamContext(ctx, "", request, response, onRecord)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// QueryMoreStreamContext streams the next batch of records of a query
+func (service *Soap) QueryMoreStreamContext(ctx context.Context, request *QueryMore, onRecord RecordHandler) (*QueryMoreResponse, error) {
+	response := new(QueryMoreResponse)
+	err := service.client.CallStreamContext(ctx, "", request, response, onRecord)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
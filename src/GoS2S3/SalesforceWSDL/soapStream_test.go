@@ -0,0 +1,177 @@
+package SalesforceWSDL
+
+import (
+	"GoS2S3/httpUtil"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+const queryResponseStart = `<?xml version="1.0" encoding="UTF-8"?>
+<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/" xmlns="urn:enterprise.soap.sforce.com" xmlns:sf="urn:sobject.enterprise.soap.sforce.com" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+<soapenv:Body><queryResponse><result xsi:type="QueryResult"><done>false</done><queryLocator>01gD0000002HU6KIAW-2000</queryLocator>`
+
+const queryResponseRecords = `<records xsi:type="sf:Account"><sf:Id>001D000000IqhSLIAZ</sf:Id><sf:Name>Acme</sf:Name></records>` +
+	`<records xsi:type="sf:Account"><sf:Id>001D000000IqhSMIAZ</sf:Id><sf:Name>Globex</sf:Name></records>` +
+	`<records xsi:type="sf:Account"><sf:Id>001D000000IqhSNIAZ</sf:Id><sf:Name>Initech</sf:Name></records>`
+
+const queryResponseEnd = `<size>3</size></result></queryResponse></soapenv:Body></soapenv:Envelope>`
+
+// streamedAccount is what the test handler reads from each records element
+type streamedAccount struct {
+	Id string `xml:"Id"`
+}
+
+// collectIds returns a handler appending the Id of each record to ids
+func collectIds(ids *[]string) RecordHandler {
+	return func(decoder *xml.Decoder, start xml.StartElement) error {
+		var account streamedAccount
+		if err := decoder.DecodeElement(&account, &start); err != nil {
+			return err
+		}
+		*ids = append(*ids, account.Id)
+		return nil
+	}
+}
+
+// queryServer answers every call with response, gzipped when the request is; with cutAfter > 0
+// only the first cutAfter bytes of response are sent although a longer body is announced
+func queryServer(t *testing.T, response string, cutAfter int, calls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		atomic.AddInt32(calls, 1)
+		compressed := request.Header.Get("Content-Encoding") == "gzip"
+		var requestBody io.Reader = request.Body
+		if compressed {
+			gzipReader, gzipError := gzip.NewReader(request.Body)
+			if gzipError != nil {
+				t.Errorf("request body is not gzipped: %v", gzipError)
+				return
+			}
+			requestBody = gzipReader
+		}
+		envelope, _ := ioutil.ReadAll(requestBody)
+		if !bytes.Contains(envelope, []byte("SELECT Id, Name FROM Account")) {
+			t.Errorf("the query is missing from the request: %s", envelope)
+		}
+
+		body := []byte(response)
+		announcedLength := len(body)
+		if cutAfter > 0 {
+			body = body[:cutAfter]
+		}
+		if compressed {
+			var compressedBody bytes.Buffer
+			gzipWriter := gzip.NewWriter(&compressedBody)
+			gzipWriter.Write(body)
+			if cutAfter > 0 {
+				// the compressed stream stops right after the bytes sent, without its end
+				gzipWriter.Flush()
+			} else {
+				gzipWriter.Close()
+			}
+			body = compressedBody.Bytes()
+			announcedLength = len(body)
+			if cutAfter > 0 {
+				announcedLength += 100
+			}
+			writer.Header().Set("Content-Encoding", "gzip")
+		}
+		writer.Header().Set("Content-Type", "text/xml; charset=utf-8")
+		writer.Header().Set("Content-Length", strconv.Itoa(announcedLength))
+		writer.Write(body)
+	}))
+}
+
+func TestQueryStream(t *testing.T) {
+	for _, compression := range []bool{false, true} {
+		var calls int32
+		server := queryServer(t, queryResponseStart+queryResponseRecords+queryResponseEnd, 0, &calls)
+
+		soap := NewSoap(server.URL, false, &BasicAuth{})
+		soap.SetCompression(compression)
+		var ids []string
+		response, queryError := soap.QueryStreamContext(context.Background(), &Query{QueryString: "SELECT Id, Name FROM Account"}, collectIds(&ids))
+		server.Close()
+		if queryError != nil {
+			t.Fatalf("compression %v: %v", compression, queryError)
+		}
+
+		if strings.Join(ids, ",") != "001D000000IqhSLIAZ,001D000000IqhSMIAZ,001D000000IqhSNIAZ" {
+			t.Errorf("compression %v: records %v, want the 3 accounts in order", compression, ids)
+		}
+		result := response.Result
+		if result == nil {
+			t.Fatalf("compression %v: no result", compression)
+		}
+		if result.Done || result.QueryLocator == nil || *result.QueryLocator != "01gD0000002HU6KIAW-2000" || result.Size != 3 {
+			t.Errorf("compression %v: done %v, locator %v, size %d, want false, 01gD0000002HU6KIAW-2000 and 3", compression, result.Done, result.QueryLocator, result.Size)
+		}
+		if len(result.Records) != 0 {
+			t.Errorf("compression %v: %d records collected in the result, the streamed ones must not be", compression, len(result.Records))
+		}
+	}
+}
+
+func TestQueryStreamCutAfterRecords(t *testing.T) {
+	for _, compression := range []bool{false, true} {
+		fullResponse := queryResponseStart + queryResponseRecords + queryResponseEnd
+		// cut in the middle of the third record
+		cutAfter := strings.Index(fullResponse, "001D000000IqhSNIAZ")
+		var calls int32
+		server := queryServer(t, fullResponse, cutAfter, &calls)
+
+		soap := NewSoap(server.URL, false, &BasicAuth{})
+		soap.SetCompression(compression)
+		retryPolicy := httpUtil.DefaultRetryPolicy()
+		retryPolicy.InitialBackoff = 0
+		soap.SetRetryPolicy(retryPolicy)
+		var ids []string
+		_, queryError := soap.QueryStreamContext(context.Background(), &Query{QueryString: "SELECT Id, Name FROM Account"}, collectIds(&ids))
+		server.Close()
+
+		if queryError == nil {
+			t.Fatalf("compression %v: a cut response must fail", compression)
+		}
+		if len(ids) != 2 {
+			t.Errorf("compression %v: %d records handed before the cut, want 2", compression, len(ids))
+		}
+		if calls != 1 {
+			t.Errorf("compression %v: %d calls, the records already handed must prevent a retry", compression, calls)
+		}
+		if retryPolicy.Retryable(queryError) {
+			t.Errorf("compression %v: %v must not be retryable", compression, queryError)
+		}
+		if !errors.Is(queryError, io.ErrUnexpectedEOF) && !strings.Contains(queryError.Error(), "unexpected EOF") {
+			t.Errorf("compression %v: %v, want the cause of the cut", compression, queryError)
+		}
+	}
+}
+
+func TestQueryStreamCutBeforeRecords(t *testing.T) {
+	fullResponse := queryResponseStart + queryResponseRecords + queryResponseEnd
+	var calls int32
+	server := queryServer(t, fullResponse, strings.Index(fullResponse, "<records"), &calls)
+	defer server.Close()
+
+	soap := NewSoap(server.URL, false, &BasicAuth{})
+	retryPolicy := httpUtil.DefaultRetryPolicy()
+	retryPolicy.InitialBackoff = 0
+	soap.SetRetryPolicy(retryPolicy)
+	var ids []string
+	if _, queryError := soap.QueryStreamContext(context.Background(), &Query{QueryString: "SELECT Id, Name FROM Account"}, collectIds(&ids)); queryError == nil {
+		t.Fatal("a cut response must fail")
+	}
+	if int(calls) != retryPolicy.MaxAttempts {
+		t.Errorf("%d calls, want %d: nothing was handed yet, the call must be retried", calls, retryPolicy.MaxAttempts)
+	}
+}
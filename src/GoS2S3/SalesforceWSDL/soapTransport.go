@@ -0,0 +1,53 @@
+package SalesforceWSDL
+
+import (
+	"GoS2S3/httpUtil"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// SetHTTPClient makes the calls go through client (ie. one with a proxy or a custom CA),
+// the connections are then reused between calls
+func (service *Soap) SetHTTPClient(client *http.Client) {
+	service.client.httpClient = client
+}
+
+// SetRetryPolicy makes the failed calls be attempted again as described by policy,
+// ie. on REQUEST_LIMIT_EXCEEDED faults or 503 responses
+func (service *Soap) SetRetryPolicy(policy httpUtil.RetryPolicy) {
+	service.client.retryPolicy = policy
+}
+
+// FaultCode exposes the fault code to the retry policy
+func (fault *SOAPFault) FaultCode() string {
+	return fault.Code
+}
+
+// SetCompression makes the calls send gzip compressed envelopes and ask for gzip compressed responses,
+// which shrinks large query and describe results
+func (service *Soap) SetCompression(enabled bool) {
+	service.client.compression = enabled
+}
+
+func gzipBytes(data []byte) ([]byte, error) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return compressed.Bytes(), nil
+}
+
+// decodedBody returns the response body, uncompressed when the server sent it gzipped
+func decodedBody(response *http.Response) (io.Reader, error) {
+	if !strings.EqualFold(response.Header.Get("Content-Encoding"), "gzip") {
+		return response.Body, nil
+	}
+	return gzip.NewReader(response.Body)
+}
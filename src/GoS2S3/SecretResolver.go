@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Configuration fields tagged with `secret:"true"` accept, instead of a plaintext value,
+// a reference resolved when the configuration is loaded:
+//   - env:NAME            the value of the environment variable NAME
+//   - file:/path/to/file  the content of the file, ie. a mounted Kubernetes/Docker secret
+//   - exec:command        the standard output of the command, run through "sh -c"
+//
+// Trailing new lines are removed from file and command values.
+const (
+	secretEnvironmentPrefix = "env:"
+	secretFilePrefix        = "file:"
+	secretCommandPrefix     = "exec:"
+)
+
+// environment variable names accepted by the env: references
+var environmentNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// resolveSecrets replaces every secret reference found in the configuration with its value
+func (connectionsConf *Configuration) resolveSecrets() error {
+	return walkSecretFields(reflect.ValueOf(connectionsConf).Elem(), "", func(fieldPath string, fieldValue reflect.Value) error {
+		secretValue, resolveError := resolveSecret(fieldValue.String())
+		if resolveError != nil {
+			return fmt.Errorf("resolving secret %s: %w", fieldPath, resolveError)
+		}
+		fieldValue.SetString(secretValue)
+		return nil
+	})
+}
+
+// validateSecretReferences reports the malformed secret references without resolving them,
+// so that the configuration can be checked where the secrets are not available (ie. in CI)
+func (connectionsConf *Configuration) validateSecretReferences(problems *ConfigurationErrors) {
+	walkSecretFields(reflect.ValueOf(connectionsConf).Elem(), "", func(fieldPath string, fieldValue reflect.Value) error {
+		if referenceError := checkSecretReference(fieldValue.String()); referenceError != nil {
+			problems.add(fieldPath, referenceError.Error())
+		}
+		return nil
+	})
+}
+
+// walkSecretFields calls visit on every field tagged as secret, stopping at the first error
+func walkSecretFields(structValue reflect.Value, path string, visit func(fieldPath string, fieldValue reflect.Value) error) error {
+	structType := structValue.Type()
+	for index := 0; index < structType.NumField(); index++ {
+		fieldType := structType.Field(index)
+		fieldValue := structValue.Field(index)
+		fieldPath := configurationKey(fieldType)
+		if path != "" {
+			fieldPath = path + "." + fieldPath
+		}
+
+		switch fieldValue.Kind() {
+		case reflect.Struct:
+			if visitError := walkSecretFields(fieldValue, fieldPath, visit); visitError != nil {
+				return visitError
+			}
+		case reflect.Slice:
+			for element := 0; element < fieldValue.Len(); element++ {
+				if fieldValue.Index(element).Kind() != reflect.Struct {
+					break
+				}
+				elementPath := fmt.Sprintf("%s[%d]", fieldPath, element)
+				if visitError := walkSecretFields(fieldValue.Index(element), elementPath, visit); visitError != nil {
+					return visitError
+				}
+			}
+		case reflect.String:
+			if fieldType.Tag.Get("secret") != "true" {
+				continue
+			}
+			if visitError := visit(fieldPath, fieldValue); visitError != nil {
+				return visitError
+			}
+		}
+	}
+	return nil
+}
+
+// isSecretReference tells if value is a reference to resolve rather than a plain value
+func isSecretReference(value string) bool {
+	return strings.HasPrefix(value, secretEnvironmentPrefix) || strings.HasPrefix(value, secretFilePrefix) || strings.HasPrefix(value, secretCommandPrefix)
+}
+
+// checkSecretReference checks the syntax of a reference, plain values are always accepted
+func checkSecretReference(reference string) error {
+	switch {
+	case strings.HasPrefix(reference, secretEnvironmentPrefix):
+		if variableName := strings.TrimPrefix(reference, secretEnvironmentPrefix); !environmentNamePattern.MatchString(variableName) {
+			return fmt.Errorf("%q is not a valid environment variable name", variableName)
+		}
+	case strings.HasPrefix(reference, secretFilePrefix):
+		if strings.TrimSpace(strings.TrimPrefix(reference, secretFilePrefix)) == "" {
+			return fmt.Errorf("file: reference without a path")
+		}
+	case strings.HasPrefix(reference, secretCommandPrefix):
+		if strings.TrimSpace(strings.TrimPrefix(reference, secretCommandPrefix)) == "" {
+			return fmt.Errorf("exec: reference without a command")
+		}
+	}
+	return nil
+}
+
+// resolveSecret returns the value pointed by reference, plain values are returned as they are
+func resolveSecret(reference string) (string, error) {
+	switch {
+	case strings.HasPrefix(reference, secretEnvironmentPrefix):
+		variableName := strings.TrimPrefix(reference, secretEnvironmentPrefix)
+		secretValue, found := os.LookupEnv(variableName)
+		if !found {
+			return "", fmt.Errorf("environment variable %s is not set", variableName)
+		}
+		return secretValue, nil
+
+	case strings.HasPrefix(reference, secretFilePrefix):
+		secretContent, readingError := os.ReadFile(strings.TrimPrefix(reference, secretFilePrefix))
+		if readingError != nil {
+			return "", readingError
+		}
+		return strings.TrimRight(string(secretContent), "\r\n"), nil
+
+	case strings.HasPrefix(reference, secretCommandPrefix):
+		var commandErrors bytes.Buffer
+		command := exec.Command("sh", "-c", strings.TrimPrefix(reference, secretCommandPrefix))
+		command.Stderr = &commandErrors
+		commandOutput, commandError := command.Output()
+		if commandError != nil {
+			return "", fmt.Errorf("command failed: %w: %s", commandError, strings.TrimSpace(commandErrors.String()))
+		}
+		return strings.TrimRight(string(commandOutput), "\r\n"), nil
+	}
+
+	return reference, nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckSecretReference(t *testing.T) {
+	tests := []struct {
+		reference string
+		valid     bool
+	}{
+		{"plain-password", true},
+		{"env:SF_PASSWORD", true},
+		{"env:", false},
+		{"env:SF PASSWORD", false},
+		{"file:/run/secrets/sf_password", true},
+		{"file:", false},
+		{"exec:vault kv get -field=password secret/sf", true},
+		{"exec: ", false},
+	}
+	for _, test := range tests {
+		if referenceError := checkSecretReference(test.reference); (referenceError == nil) != test.valid {
+			t.Errorf("checkSecretReference(%q) = %v, want valid %v", test.reference, referenceError, test.valid)
+		}
+	}
+}
+
+// validate-config must report the configuration without the secrets being available
+func TestValidateWithoutResolvingSecrets(t *testing.T) {
+	configFileName := filepath.Join(t.TempDir(), "application-config.json")
+	configFile := `{
+		"Salesforce": {"Username": "user", "ClientId": "client", "Password": "env:GOS2S3_TEST_UNSET_PASSWORD",
+			"ClientSecret": "exec:exit 1", "PrivateKey": "file:/missing/salesforce.key", "SecurityToken": "env:not a name"},
+		"AWS": {"Region": "eu-west-1", "s3_destination_bucket": "backups"}
+	}`
+	if writeError := ioutil.WriteFile(configFileName, []byte(configFile), 0600); writeError != nil {
+		t.Fatal(writeError)
+	}
+	flagSet := flag.NewFlagSet("test", flag.ContinueOnError)
+	configuration, loadError := loadConfiguration(configFileName, flagSet, registerConfigurationFlags(flagSet), false)
+	if loadError != nil {
+		t.Fatalf("loading without resolving the secrets: %v", loadError)
+	}
+	if configuration.Salesforce.Password != "env:GOS2S3_TEST_UNSET_PASSWORD" {
+		t.Errorf("Password = %q, the reference must be left as it is", configuration.Salesforce.Password)
+	}
+
+	validationError := configuration.Validate()
+	if validationError == nil || !strings.Contains(validationError.Error(), "Salesforce.SecurityToken") {
+		t.Fatalf("the malformed SecurityToken reference must be reported, got %v", validationError)
+	}
+	if problems := validationError.(ConfigurationErrors); len(problems) != 1 {
+		t.Errorf("only the malformed reference must be reported, got:\n%v", validationError)
+	}
+}
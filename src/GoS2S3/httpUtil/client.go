@@ -0,0 +1,152 @@
+package httpUtil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"golang.org/x/net/http/httpproxy"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Options configures the HTTP client shared by every call to Salesforce and AWS,
+// zero values keep the defaults below
+type Options struct {
+	// proxy for every request, when empty the HTTP_PROXY, HTTPS_PROXY and NO_PROXY variables are used
+	ProxyURL string
+	// comma separated hosts, domains and CIDRs reached without the proxy, ie. "169.254.169.254,.internal"
+	NoProxy string
+	// PEM bundle of the certificate authorities trusted on top of the system ones
+	CAFile string
+	// PEM certificate and key presented when the server asks for a client certificate
+	ClientCertFile string
+	ClientKeyFile  string
+
+	DialTimeout           time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+	IdleConnTimeout       time.Duration
+	KeepAlive             time.Duration
+	DisableKeepAlives     bool
+	MaxIdleConnsPerHost   int
+	// "1.0", "1.1", "1.2" or "1.3", 1.2 when empty
+	TLSMinVersion string
+}
+
+const (
+	defaultDialTimeout         = 30 * time.Second
+	defaultKeepAlive           = 30 * time.Second
+	defaultTLSHandshakeTimeout = 10 * time.Second
+	defaultIdleConnTimeout     = 90 * time.Second
+	defaultMaxIdleConnsPerHost = 10
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+var defaultClient = &http.Client{Transport: NewTracingTransport(nil)}
+
+// DefaultClient is the client used when none was injected, it only traces the traffic
+func DefaultClient() *http.Client {
+	return defaultClient
+}
+
+// NewHTTPClient builds a client from options, its traffic is traced when the trace is on.
+// The connections are kept alive and reused unless DisableKeepAlives is set.
+func NewHTTPClient(options Options) (*http.Client, error) {
+	transport, transportError := NewTransport(options)
+	if transportError != nil {
+		return nil, transportError
+	}
+	return &http.Client{Transport: NewTracingTransport(transport)}, nil
+}
+
+// NewTransport builds the transport described by options
+func NewTransport(options Options) (*http.Transport, error) {
+	tlsConfig, tlsError := options.tlsConfig()
+	if tlsError != nil {
+		return nil, tlsError
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if options.ProxyURL != "" {
+		if _, parseError := url.Parse(options.ProxyURL); parseError != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", parseError)
+		}
+		proxyConfig := httpproxy.Config{HTTPProxy: options.ProxyURL, HTTPSProxy: options.ProxyURL, NoProxy: options.NoProxy}
+		proxyFunc := proxyConfig.ProxyFunc()
+		proxy = func(request *http.Request) (*url.URL, error) {
+			return proxyFunc(request.URL)
+		}
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   valueOrDefault(options.DialTimeout, defaultDialTimeout),
+		KeepAlive: valueOrDefault(options.KeepAlive, defaultKeepAlive),
+	}
+	maxIdleConnsPerHost := options.MaxIdleConnsPerHost
+	if maxIdleConnsPerHost <= 0 {
+		maxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	}
+
+	return &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   valueOrDefault(options.TLSHandshakeTimeout, defaultTLSHandshakeTimeout),
+		ResponseHeaderTimeout: options.ResponseHeaderTimeout,
+		IdleConnTimeout:       valueOrDefault(options.IdleConnTimeout, defaultIdleConnTimeout),
+		DisableKeepAlives:     options.DisableKeepAlives,
+		MaxIdleConnsPerHost:   maxIdleConnsPerHost,
+		ForceAttemptHTTP2:     true,
+	}, nil
+}
+
+func (options Options) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if options.TLSMinVersion != "" {
+		minVersion, known := tlsVersions[options.TLSMinVersion]
+		if !known {
+			return nil, fmt.Errorf("unknown TLS version %q", options.TLSMinVersion)
+		}
+		tlsConfig.MinVersion = minVersion
+	}
+
+	if options.CAFile != "" {
+		rootCAs, poolError := x509.SystemCertPool()
+		if poolError != nil || rootCAs == nil {
+			rootCAs = x509.NewCertPool()
+		}
+		bundle, readError := ioutil.ReadFile(options.CAFile)
+		if readError != nil {
+			return nil, fmt.Errorf("reading the CA bundle: %w", readError)
+		}
+		if !rootCAs.AppendCertsFromPEM(bundle) {
+			return nil, fmt.Errorf("no PEM certificate found in %s", options.CAFile)
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	if options.ClientCertFile != "" || options.ClientKeyFile != "" {
+		certificate, certificateError := tls.LoadX509KeyPair(options.ClientCertFile, options.ClientKeyFile)
+		if certificateError != nil {
+			return nil, fmt.Errorf("loading the client certificate: %w", certificateError)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	return tlsConfig, nil
+}
+
+func valueOrDefault(value time.Duration, defaultValue time.Duration) time.Duration {
+	if value > 0 {
+		return value
+	}
+	return defaultValue
+}
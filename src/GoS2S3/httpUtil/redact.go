@@ -0,0 +1,62 @@
+package httpUtil
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const redactedValue = "***"
+
+// headers never written to the trace, the AWS ones carrying the session token and the instance metadata token
+var sensitiveHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "X-Amz-Security-Token", "X-Aws-Ec2-Metadata-Token"}
+
+// names of the XML elements, JSON fields, form and query parameters holding credentials or sessions:
+// the Salesforce and OAuth ones, then the AWS ones of STS, of the instance metadata and of presigned URLs
+const sensitiveNames = `password|securityToken|sessionId|sid|access_token|refresh_token|id_token|client_secret|assertion|signature|` +
+	`SecretAccessKey|SessionToken|WebIdentityToken|Token|X-Amz-Security-Token|X-Amz-Signature`
+
+var (
+	sensitiveXMLElement = regexp.MustCompile(`(?i)(<(?:[\w-]+:)?(?:` + sensitiveNames + `)(?:\s[^>]*)?>)[^<]*(</)`)
+	sensitiveJSONField  = regexp.MustCompile(`(?i)("(?:` + sensitiveNames + `)"\s*:\s*")(?:[^"\\]|\\.)*(")`)
+	sensitiveParameter  = regexp.MustCompile(`(?i)((?:^|[?&\s])(?:` + sensitiveNames + `)=)[^&\s"'<]*`)
+	sensitiveName       = regexp.MustCompile(`(?i)^(?:` + sensitiveNames + `)$`)
+)
+
+// Redact replaces the passwords, session ids and tokens found in an XML, JSON or form encoded text
+func Redact(text string) string {
+	text = sensitiveXMLElement.ReplaceAllString(text, "${1}"+redactedValue+"${2}")
+	text = sensitiveJSONField.ReplaceAllString(text, "${1}"+redactedValue+"${2}")
+	return sensitiveParameter.ReplaceAllString(text, "${1}"+redactedValue)
+}
+
+// RedactURL hides the sensitive query parameters of rawURL
+func RedactURL(rawURL *url.URL) string {
+	if rawURL == nil {
+		return ""
+	}
+	redactedURL := *rawURL
+	redactedURL.User = nil
+	query := redactedURL.Query()
+	for name := range query {
+		if sensitiveName.MatchString(name) {
+			query.Set(name, redactedValue)
+		}
+	}
+	redactedURL.RawQuery = query.Encode()
+	return redactedURL.String()
+}
+
+// redactHeaders returns the headers as "Name: value" lines, without the credentials and cookies
+func redactHeaders(headers http.Header) string {
+	redactedHeaders := headers.Clone()
+	for _, name := range sensitiveHeaders {
+		if redactedHeaders.Get(name) != "" {
+			redactedHeaders.Set(name, redactedValue)
+		}
+	}
+	var lines strings.Builder
+	redactedHeaders.Write(&lines)
+	return lines.String()
+}
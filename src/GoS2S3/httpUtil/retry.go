@@ -0,0 +1,223 @@
+package httpUtil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy tells how many times and how often a failed call is attempted again.
+// A policy with MaxAttempts lower than 2 performs a single attempt.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// fraction of each delay randomly added or removed, so that parallel orgs don't retry in lockstep
+	Jitter               float64
+	RetryableStatusCodes []int
+	// matched against the end of the fault code, ie. "REQUEST_LIMIT_EXCEEDED" matches "sf:REQUEST_LIMIT_EXCEEDED"
+	RetryableFaultCodes []string
+}
+
+// DefaultRetryPolicy retries 3 times in about 7 seconds the throttling and server errors
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:          4,
+		InitialBackoff:       time.Second,
+		MaxBackoff:           30 * time.Second,
+		Jitter:               0.2,
+		RetryableStatusCodes: []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+		RetryableFaultCodes:  []string{"REQUEST_LIMIT_EXCEEDED", "SERVER_UNAVAILABLE"},
+	}
+}
+
+// StatusError is returned by a call answered with an unexpected HTTP status
+type StatusError struct {
+	Operation  string
+	StatusCode int
+	Status     string
+}
+
+func (statusError *StatusError) Error() string {
+	return fmt.Sprintf("%s: unexpected status %s", statusError.Operation, statusError.Status)
+}
+
+// HTTPStatusCode makes the status visible to the retry policy
+func (statusError *StatusError) HTTPStatusCode() int {
+	return statusError.StatusCode
+}
+
+// errors exposing the HTTP status or the SOAP fault code they come from
+type statusCoder interface{ HTTPStatusCode() int }
+type faultCoder interface{ FaultCode() string }
+
+// the AWS SDK request failures expose their status this way
+type awsStatusCoder interface{ StatusCode() int }
+
+// the AWS SDK errors expose their causes this way rather than with Unwrap, ie. s3manager wraps
+// the failure of a part in a "MultipartUpload" error
+type awsCauser interface{ OrigErr() error }
+type awsBatchCauser interface{ OrigErrs() []error }
+
+type retryableError struct {
+	err error
+}
+
+func (markedError *retryableError) Error() string {
+	return markedError.err.Error()
+}
+
+func (markedError *retryableError) Unwrap() error {
+	return markedError.err
+}
+
+// MarkRetryable flags err as transient when only the caller knows it is, ie. a throttled AWS request
+func MarkRetryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &retryableError{err: err}
+}
+
+type permanentError struct {
+	err error
+}
+
+func (markedError *permanentError) Error() string {
+	return markedError.err.Error()
+}
+
+func (markedError *permanentError) Unwrap() error {
+	return markedError.err
+}
+
+// MarkPermanent flags err as not worth another attempt whatever its cause, ie. a streamed
+// response failing after part of it was already handed to the caller
+func MarkPermanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// Retryable tells if err is worth another attempt: network failures, the retryable statuses
+// and fault codes of the policy, or errors marked with MarkRetryable. Cancellations and errors
+// marked with MarkPermanent never are.
+func (policy RetryPolicy) Retryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var permanent *permanentError
+	if errors.As(err, &permanent) {
+		return false
+	}
+	var markedError *retryableError
+	if errors.As(err, &markedError) {
+		return true
+	}
+	var statusError statusCoder
+	if errors.As(err, &statusError) {
+		return policy.RetryableStatus(statusError.HTTPStatusCode())
+	}
+	var awsError awsStatusCoder
+	if errors.As(err, &awsError) && policy.RetryableStatus(awsError.StatusCode()) {
+		return true
+	}
+	for _, cause := range awsCauses(err) {
+		if policy.Retryable(cause) {
+			return true
+		}
+	}
+	var faultError faultCoder
+	if errors.As(err, &faultError) {
+		for _, faultCode := range policy.RetryableFaultCodes {
+			if strings.HasSuffix(faultError.FaultCode(), faultCode) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var networkError net.Error
+	return errors.As(err, &networkError) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// awsCauses returns the errors an AWS SDK error was built from
+func awsCauses(err error) []error {
+	var batchError awsBatchCauser
+	if errors.As(err, &batchError) {
+		return batchError.OrigErrs()
+	}
+	var causeError awsCauser
+	if errors.As(err, &causeError) && causeError.OrigErr() != nil {
+		return []error{causeError.OrigErr()}
+	}
+	return nil
+}
+
+// RetryableStatus tells if a response with statusCode is worth another attempt
+func (policy RetryPolicy) RetryableStatus(statusCode int) bool {
+	for _, retryableStatusCode := range policy.RetryableStatusCodes {
+		if statusCode == retryableStatusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// Do runs attempt until it succeeds, fails with a non retryable error, the attempts are exhausted
+// or ctx is done. Every failed attempt is logged with its number.
+func (policy RetryPolicy) Do(ctx context.Context, operation string, attempt func() error) error {
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	for attemptNumber := 1; ; attemptNumber++ {
+		attemptError := attempt()
+		if attemptError == nil {
+			if attemptNumber > 1 {
+				log.Printf("%s succeeded at attempt %d/%d", operation, attemptNumber, maxAttempts)
+			}
+			return nil
+		}
+		if attemptNumber >= maxAttempts || !policy.Retryable(attemptError) {
+			if attemptNumber > 1 {
+				return fmt.Errorf("%s failed after %d attempts: %w", operation, attemptNumber, attemptError)
+			}
+			return attemptError
+		}
+
+		delay := policy.backoff(attemptNumber)
+		log.Printf("%s attempt %d/%d failed, retrying in %s: %v", operation, attemptNumber, maxAttempts, delay.Round(time.Millisecond), attemptError)
+		select {
+		case <-ctx.Done():
+			return attemptError
+		case <-time.After(delay):
+		}
+	}
+}
+
+// backoff doubles the delay after each attempt up to MaxBackoff, then applies the jitter
+func (policy RetryPolicy) backoff(attemptNumber int) time.Duration {
+	delay := policy.InitialBackoff
+	for index := 1; index < attemptNumber && (policy.MaxBackoff <= 0 || delay < policy.MaxBackoff); index++ {
+		delay *= 2
+	}
+	if policy.MaxBackoff > 0 && delay > policy.MaxBackoff {
+		delay = policy.MaxBackoff
+	}
+	if policy.Jitter > 0 {
+		delay += time.Duration((rand.Float64()*2 - 1) * policy.Jitter * float64(delay))
+	}
+	return delay
+}
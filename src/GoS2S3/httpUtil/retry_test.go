@@ -0,0 +1,141 @@
+package httpUtil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type testFault struct{ code string }
+
+func (fault *testFault) Error() string     { return fault.code }
+func (fault *testFault) FaultCode() string { return fault.code }
+
+// policy retrying right away so that the tests never wait
+func immediateRetryPolicy(maxAttempts int) RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.MaxAttempts = maxAttempts
+	policy.InitialBackoff = 0
+	policy.Jitter = 0
+	return policy
+}
+
+func TestRetryable(t *testing.T) {
+	policy := DefaultRetryPolicy()
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{"no error", nil, false},
+		{"throttled", &StatusError{Operation: "download", StatusCode: http.StatusTooManyRequests}, true},
+		{"unavailable", &StatusError{Operation: "download", StatusCode: http.StatusServiceUnavailable}, true},
+		{"not found", &StatusError{Operation: "download", StatusCode: http.StatusNotFound}, false},
+		{"wrapped status", fmt.Errorf("export page: %w", &StatusError{StatusCode: http.StatusBadGateway}), true},
+		{"retryable fault", &testFault{"sf:REQUEST_LIMIT_EXCEEDED"}, true},
+		{"retryable fault without prefix", &testFault{"SERVER_UNAVAILABLE"}, true},
+		{"other fault", &testFault{"sf:INVALID_SESSION_ID"}, false},
+		{"fault code only ending like a retryable one", &testFault{"sf:REQUEST_LIMIT_EXCEEDED_SOON"}, false},
+		{"network error", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{"truncated body", fmt.Errorf("reading: %w", io.ErrUnexpectedEOF), true},
+		{"cancelled", context.Canceled, false},
+		{"deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), false},
+		{"marked retryable", MarkRetryable(errors.New("SlowDown")), true},
+		{"marked permanent", MarkPermanent(&StatusError{StatusCode: http.StatusServiceUnavailable}), false},
+		{"permanent over retryable", MarkPermanent(MarkRetryable(errors.New("SlowDown"))), false},
+		{"plain error", errors.New("unexpected element"), false},
+		{"AWS request failure", awserr.NewRequestFailure(awserr.New("SlowDown", "Please reduce your request rate.", nil), http.StatusServiceUnavailable, "request-id"), true},
+		{"multipart upload with a failed part", awserr.New("MultipartUpload", "upload multipart failed", awserr.NewRequestFailure(awserr.New("ServiceUnavailable", "Service Unavailable", nil), http.StatusServiceUnavailable, "request-id")), true},
+		{"multipart upload with an internal error", fmt.Errorf("uploading: %w", awserr.New("MultipartUpload", "upload multipart failed", awserr.NewRequestFailure(awserr.New("InternalError", "We encountered an internal error.", nil), http.StatusInternalServerError, "request-id"))), true},
+		{"batched errors", awserr.NewBatchError("BatchedErrors", "multiple errors", []error{errors.New("aborted"), awserr.NewRequestFailure(awserr.New("SlowDown", "", nil), http.StatusServiceUnavailable, "")}), true},
+		{"multipart upload refused", awserr.New("MultipartUpload", "upload multipart failed", awserr.NewRequestFailure(awserr.New("AccessDenied", "Access Denied", nil), http.StatusForbidden, "request-id")), false},
+		{"multipart upload cancelled", awserr.New("MultipartUpload", "upload multipart failed", awserr.New("RequestCanceled", "request context canceled", context.Canceled)), false},
+	}
+	for _, test := range tests {
+		if retryable := policy.Retryable(test.err); retryable != test.retryable {
+			t.Errorf("%s: Retryable(%v) = %v, want %v", test.name, test.err, retryable, test.retryable)
+		}
+	}
+}
+
+func TestDoAttempts(t *testing.T) {
+	throttled := &StatusError{Operation: "query", StatusCode: http.StatusServiceUnavailable, Status: "503 Service Unavailable"}
+	tests := []struct {
+		name        string
+		maxAttempts int
+		failures    []error // error of each attempt, the following ones succeed
+		attempts    int
+		failed      bool
+	}{
+		{name: "first attempt succeeds", maxAttempts: 4, attempts: 1},
+		{name: "succeeds after retries", maxAttempts: 4, failures: []error{throttled, throttled}, attempts: 3},
+		{name: "attempts exhausted", maxAttempts: 3, failures: []error{throttled, throttled, throttled, throttled}, attempts: 3, failed: true},
+		{name: "not retryable", maxAttempts: 4, failures: []error{&testFault{"sf:INVALID_FIELD"}}, attempts: 1, failed: true},
+		{name: "single attempt policy", maxAttempts: 0, failures: []error{throttled}, attempts: 1, failed: true},
+		{name: "permanent after a retry", maxAttempts: 4, failures: []error{throttled, MarkPermanent(throttled)}, attempts: 2, failed: true},
+	}
+	for _, test := range tests {
+		attempts := 0
+		doError := immediateRetryPolicy(test.maxAttempts).Do(context.Background(), "query", func() error {
+			attempts++
+			if attempts <= len(test.failures) {
+				return test.failures[attempts-1]
+			}
+			return nil
+		})
+		if attempts != test.attempts {
+			t.Errorf("%s: %d attempts, want %d", test.name, attempts, test.attempts)
+		}
+		if (doError != nil) != test.failed {
+			t.Errorf("%s: Do() = %v, want failed=%v", test.name, doError, test.failed)
+		}
+		if doError != nil && !errors.Is(doError, test.failures[attempts-1]) {
+			t.Errorf("%s: Do() = %v, must wrap the last attempt error", test.name, doError)
+		}
+	}
+}
+
+func TestDoStopsWhenCancelled(t *testing.T) {
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Hour
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	doError := policy.Do(ctx, "download", func() error {
+		attempts++
+		cancel()
+		return MarkRetryable(errors.New("SlowDown"))
+	})
+	if attempts != 1 || doError == nil {
+		t.Errorf("Do() = %v after %d attempts, want the error of the single attempt", doError, attempts)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 10 * time.Second}
+	for attemptNumber, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second} {
+		if delay := policy.backoff(attemptNumber + 1); delay != expected {
+			t.Errorf("backoff(%d) = %s, want %s", attemptNumber+1, delay, expected)
+		}
+	}
+
+	policy.Jitter = 0.2
+	for attemptNumber := 1; attemptNumber <= 6; attemptNumber++ {
+		base := RetryPolicy{InitialBackoff: policy.InitialBackoff, MaxBackoff: policy.MaxBackoff}.backoff(attemptNumber)
+		minimum, maximum := time.Duration(float64(base)*0.8), time.Duration(float64(base)*1.2)
+		for draw := 0; draw < 100; draw++ {
+			if delay := policy.backoff(attemptNumber); delay < minimum || delay > maximum {
+				t.Fatalf("backoff(%d) = %s with jitter, want between %s and %s", attemptNumber, delay, minimum, maximum)
+			}
+		}
+	}
+
+	if delay := (RetryPolicy{}).backoff(3); delay != 0 {
+		t.Errorf("backoff without InitialBackoff = %s, want 0", delay)
+	}
+}
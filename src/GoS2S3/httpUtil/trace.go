@@ -0,0 +1,108 @@
+// Package httpUtil holds the HTTP helpers shared by the Salesforce clients, the SOAP client and the downloader
+package httpUtil
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// maximum number of body bytes written to the trace for each request or response
+const maxTracedBodyLength = 64 * 1024
+
+var traceEnabled bool
+
+// EnableTrace turns the wire trace on or off for every client built by this package.
+// The trace logs the requests and the responses with passwords, session ids and tokens redacted.
+func EnableTrace(enabled bool) {
+	traceEnabled = enabled
+}
+
+// TraceEnabled tells if the wire trace is on
+func TraceEnabled() bool {
+	return traceEnabled
+}
+
+// NewTracingTransport wraps base (http.DefaultTransport when nil) so that it logs the traffic when the trace is on
+func NewTracingTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &tracingTransport{base: base}
+}
+
+type tracingTransport struct {
+	base http.RoundTripper
+}
+
+func (transport *tracingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if !traceEnabled {
+		return transport.base.RoundTrip(request)
+	}
+
+	log.Printf("--> %s %s\n%s%s", request.Method, RedactURL(request.URL), redactHeaders(request.Header), traceRequestBody(request))
+	response, responseError := transport.base.RoundTrip(request)
+	if responseError != nil {
+		log.Printf("<-- %s %s failed: %v", request.Method, RedactURL(request.URL), responseError)
+		return response, responseError
+	}
+	log.Printf("<-- %s %s\n%s%s", response.Status, RedactURL(request.URL), redactHeaders(response.Header), traceResponseBody(response))
+	return response, nil
+}
+
+// traceRequestBody reads a copy of the body, the request itself is left untouched
+func traceRequestBody(request *http.Request) string {
+	if request.Body == nil || request.GetBody == nil {
+		return ""
+	}
+	if !isTextual(request.Header.Get("Content-Type")) {
+		return "[binary body]\n"
+	}
+	bodyCopy, copyError := request.GetBody()
+	if copyError != nil {
+		return ""
+	}
+	defer bodyCopy.Close()
+	body, _ := ioutil.ReadAll(io.LimitReader(bodyCopy, maxTracedBodyLength))
+	return Redact(decodedForTrace(request.Header.Get("Content-Encoding"), body)) + "\n"
+}
+
+// traceResponseBody logs the beginning of a textual body and puts it back in front of the rest,
+// so that large downloads are still streamed and binary ones are never read
+func traceResponseBody(response *http.Response) string {
+	if response.Body == nil || !isTextual(response.Header.Get("Content-Type")) {
+		return "[binary body]\n"
+	}
+	bodyStart, _ := ioutil.ReadAll(io.LimitReader(response.Body, maxTracedBodyLength))
+	response.Body = &replayedBody{Reader: io.MultiReader(bytes.NewReader(bodyStart), response.Body), Closer: response.Body}
+	return Redact(decodedForTrace(response.Header.Get("Content-Encoding"), bodyStart)) + "\n"
+}
+
+// decodedForTrace uncompresses a gzip body, the traced part may be truncated so decoding errors are ignored
+func decodedForTrace(contentEncoding string, body []byte) string {
+	if !strings.EqualFold(contentEncoding, "gzip") {
+		return string(body)
+	}
+	reader, readerError := gzip.NewReader(bytes.NewReader(body))
+	if readerError != nil {
+		return "[gzip body]"
+	}
+	decoded, _ := ioutil.ReadAll(io.LimitReader(reader, maxTracedBodyLength))
+	return string(decoded)
+}
+
+type replayedBody struct {
+	io.Reader
+	io.Closer
+}
+
+func isTextual(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return strings.HasPrefix(mediaType, "text/") || strings.HasSuffix(mediaType, "json") || strings.HasSuffix(mediaType, "xml") ||
+		mediaType == "application/x-www-form-urlencoded"
+}
@@ -1,60 +1,97 @@
 package salesforceUtil
 
 import (
+	"GoS2S3/SalesforceWSDL"
+	"context"
+	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
-	"os"
 	"strings"
-	"encoding/json"
-	"io/ioutil"
-	"GoS2S3/SalesforceWSDL"
+	"time"
 )
 
-func (connection *SF_connection) GetSoapEndpoint() string {
-	client := &http.Client{}
+// identity is the subset of the identity service response needed to reach the SOAP API
+type identity struct {
+	Organization_id string            `json:"organization_id"`
+	Urls            map[string]string `json:"urls"`
+}
+
+// GetSoapEndpoint queries the identity URL returned by the OAuth login and returns the enterprise SOAP endpoint
+func (connection *SF_connection) GetSoapEndpoint(ctx context.Context) (string, error) {
+	const operation = "SOAP endpoint discovery"
+
 	targetInstance := connection.AuthenticationToken.Id // contains the url you have to query to get the SOAP endpoint
-	
-	request, _ := http.NewRequest("POST", targetInstance, nil)
-	request.Header.Add("Authorization", connection.AuthenticationToken.Token_type + " " + connection.AuthenticationToken.Access_token)
-
-	response, responseError := client.Do(request)
-	if responseError != nil {
-		response.Body.Close()
-		log.Println("ERROR: Failed retrieving SOAP endpoint!")
-		if connection.Debug {
-			log.Println(responseError)
-		} else {
-			log.Println("Error: an error occurred during the execution, please run with -debug for more information")
-		}
-		
-		os.Exit(1)
+	if targetInstance == "" {
+		return "", &MissingFieldError{Operation: operation, Field: "id"}
 	}
-	defer response.Body.Close() 
-	
-	body, _ := ioutil.ReadAll(response.Body)
-	// fmt.Printf("%s\n", string(body))
-	// fmt.Println(string(body))
-	var jsonBody map[string] interface {}
-	if err := json.Unmarshal(body, &jsonBody); err != nil {
-        panic(err)
+
+	request, requestError := http.NewRequestWithContext(ctx, "POST", targetInstance, nil)
+	if requestError != nil {
+		return "", requestError
 	}
-	connection.OrganizationId = jsonBody["organization_id"].(string)	
-	soapEndpoint := (jsonBody["urls"].(map[string] interface {}))["enterprise"]
+	request.Header.Add("Authorization", connection.AuthenticationToken.Token_type+" "+connection.AuthenticationToken.Access_token)
 
-	return soapEndpoint.(string)
+	response, body, fetchError := connection.fetch(ctx, operation, targetInstance, func() (*http.Response, error) {
+		return connection.httpClient().Do(request)
+	})
+	if fetchError != nil {
+		return "", fetchError
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", errorFromResponse(operation, response, body)
+	}
+
+	var identityBody identity
+	if err := json.Unmarshal(body, &identityBody); err != nil {
+		return "", unexpectedResponse(operation, response.StatusCode, body, err)
+	}
+	if identityBody.Organization_id == "" {
+		return "", &MissingFieldError{Operation: operation, Field: "organization_id"}
+	}
+	soapEndpoint := identityBody.Urls["enterprise"]
+	if soapEndpoint == "" {
+		return "", &MissingFieldError{Operation: operation, Field: "urls.enterprise"}
+	}
+	connection.OrganizationId = identityBody.Organization_id
+
+	return soapEndpoint, nil
 }
 
-func (connection *SF_connection) AuthenticateThroughSOAP() (SalesforceWSDL.Soap, SalesforceWSDL.BasicAuth) {
+// AuthenticateThroughSOAP opens the SOAP session used by the API calls and by the download cookies.
+// A rejected SOAP login is reported as *AuthenticationError.
+func (connection *SF_connection) AuthenticateThroughSOAP(ctx context.Context) (SalesforceWSDL.Soap, SalesforceWSDL.BasicAuth, error) {
+	const operation = "SOAP login"
+
 	var SF_Soap SalesforceWSDL.Soap
 	var SF_BasicAuth SalesforceWSDL.BasicAuth
-	
+
 	SF_BasicAuth.Login = connection.Username
 	SF_BasicAuth.Password = connection.Password
-	
-	connection.SoapEndpoint = connection.GetSoapEndpoint()
-	connection.SoapEndpoint = strings.Replace(connection.SoapEndpoint, "{version}", "v43.0", 1)
-		
-	SF_Soap = *SalesforceWSDL.NewSoap(connection.SoapEndpoint, false, &SF_BasicAuth)
+
+	soapEndpoint, endpointError := connection.GetSoapEndpoint(ctx)
+	if endpointError != nil {
+		return SF_Soap, SF_BasicAuth, endpointError
+	}
+	connection.SoapEndpoint = strings.Replace(soapEndpoint, "{version}", connection.APIVersion(), 1)
+
+	// without a password (ie. JWT bearer flow) the OAuth access token is used as session id
+	if connection.GrantType != "" && connection.GrantType != GrantTypePassword {
+		log.Println("Using the OAuth access token as SOAP session")
+		SF_Soap = *connection.newSoap(connection.SoapEndpoint, &SF_BasicAuth)
+		connection.SoapLogin.SessionId = connection.AuthenticationToken.Access_token
+		connection.SoapLogin.ServerUrl = connection.SoapEndpoint
+		connection.startSession(&SF_Soap)
+		return SF_Soap, SF_BasicAuth, nil
+	}
+
+	// the SOAP login goes to the same host as the OAuth one, the following calls to the
+	// server URL returned by the login
+	soapLoginURL, urlError := connection.soapLoginURL()
+	if urlError != nil {
+		return SF_Soap, SF_BasicAuth, urlError
+	}
+	loginSoap := connection.newSoap(soapLoginURL, &SF_BasicAuth)
 
 	var loginAttempt SalesforceWSDL.Login
 	loginAttempt.Username = connection.Username
@@ -64,23 +101,57 @@ func (connection *SF_connection) AuthenticateThroughSOAP() (SalesforceWSDL.Soap,
 	log.Printf("Logging in through SOAP ....")
 	log.Println("")
 
-	loginResponse, loginError := SF_Soap.Login(&loginAttempt)
-	if(loginError != nil) {
-		//fmt.Printf(" [FAILED]\n")
-		log.Println("ERROR during Salesforce SOAP login")
-		if connection.Debug {
-			log.Println(loginError)
+	loginResponse, loginError := loginSoap.LoginContext(ctx, &loginAttempt)
+	if loginError != nil {
+		var loginFault *SalesforceWSDL.LoginFault
+		if errors.As(loginError, &loginFault) {
+			return SF_Soap, SF_BasicAuth, &AuthenticationError{Code: loginFault.Code(), Description: loginFault.ExceptionMessage}
 		}
-	} else {
-		//fmt.Printf(" [SUCCESS]\n")
-		log.Println("LOGIN SUCCESSFUL!")
-		if connection.Debug {
-			log.Println("LOGIN RESPONSE: ")
-			log.Println(loginResponse)
+		var fault *SalesforceWSDL.SOAPFault
+		if errors.As(loginError, &fault) {
+			return SF_Soap, SF_BasicAuth, &AuthenticationError{Code: fault.Code, Description: fault.String}
 		}
-		connection.SoapLogin = *loginResponse.Result		
+		return SF_Soap, SF_BasicAuth, &NetworkError{Operation: operation, URL: soapLoginURL, Err: loginError}
+	}
+	if loginResponse.Result == nil || loginResponse.Result.SessionId == "" {
+		return SF_Soap, SF_BasicAuth, &MissingFieldError{Operation: operation, Field: "sessionId"}
+	}
+	if loginResponse.Result.ServerUrl == "" {
+		return SF_Soap, SF_BasicAuth, &MissingFieldError{Operation: operation, Field: "serverUrl"}
+	}
+
+	log.Println("LOGIN SUCCESSFUL!")
+	if connection.Debug {
+		log.Printf("SOAP server URL: %s", loginResponse.Result.ServerUrl)
 	}
+	connection.SoapLogin = *loginResponse.Result
+	connection.SoapEndpoint = connection.SoapLogin.ServerUrl
+	SF_Soap = *loginSoap
+
+	connection.startSession(&SF_Soap)
+	return SF_Soap, SF_BasicAuth, nil
+}
+
+// newSoap returns a SOAP client of endpoint sending its calls through the HTTP client of the connection
+func (connection *SF_connection) newSoap(endpoint string, auth *SalesforceWSDL.BasicAuth) *SalesforceWSDL.Soap {
+	soap := SalesforceWSDL.NewSoap(endpoint, false, auth)
+	soap.SetHTTPClient(connection.httpClient())
+	soap.SetRetryPolicy(connection.RetryPolicy)
+	soap.SetCompression(connection.Compression)
+	return soap
+}
+
+// startSession makes the new session the one used by the SOAP calls, sent to its server URL, and by the download cookies
+func (connection *SF_connection) startSession(soap *SalesforceWSDL.Soap) {
+	soap.SetSession(&connection.SoapLogin)
+	connection.Soap = soap
+	connection.SessionStarted = time.Now()
 	connection.ConnectionCookies["oid"] = connection.OrganizationId
-	connection.ConnectionCookies["sid"] = loginResponse.Result.SessionId
-	return SF_Soap, SF_BasicAuth
-}
\ No newline at end of file
+	connection.ConnectionCookies["sid"] = connection.SoapLogin.SessionId
+
+	if connection.TokenCacheEnabled() {
+		if cacheError := connection.saveCachedSession(); cacheError != nil {
+			log.Printf("Error saving the session to the token cache: %v", cacheError)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package salesforceUtil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DefaultAPIVersion matches the enterprise WSDL the SalesforceWSDL package is generated from
+const DefaultAPIVersion = "44.0"
+
+// UnsupportedAPIVersionError is returned when the org doesn't offer the requested API version
+type UnsupportedAPIVersionError struct {
+	Requested string
+	Available []string
+}
+
+func (versionError *UnsupportedAPIVersionError) Error() string {
+	return fmt.Sprintf("API version %s is not available on this org (available: %s)", versionError.Requested, strings.Join(versionError.Available, ", "))
+}
+
+// APIVersion returns the configured API version, like "44.0", or DefaultAPIVersion
+func (connection *SF_connection) APIVersion() string {
+	if connection.ApiVersion != "" {
+		return strings.TrimPrefix(connection.ApiVersion, "v")
+	}
+	return DefaultAPIVersion
+}
+
+// SupportedAPIVersions lists the API versions offered by the instance through /services/data
+func (connection *SF_connection) SupportedAPIVersions(ctx context.Context) ([]string, error) {
+	const operation = "API versions listing"
+
+	versionsURL, urlError := connection.InstanceURL("/services/data")
+	if urlError != nil {
+		return nil, urlError
+	}
+
+	request, requestError := http.NewRequestWithContext(ctx, "GET", versionsURL, nil)
+	if requestError != nil {
+		return nil, requestError
+	}
+	response, body, fetchError := connection.fetch(ctx, operation, versionsURL, func() (*http.Response, error) {
+		return connection.httpClient().Do(request)
+	})
+	if fetchError != nil {
+		return nil, fetchError
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, errorFromResponse(operation, response, body)
+	}
+
+	var versionsBody []struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(body, &versionsBody); err != nil {
+		return nil, unexpectedResponse(operation, response.StatusCode, body, err)
+	}
+
+	versions := make([]string, 0, len(versionsBody))
+	for _, version := range versionsBody {
+		versions = append(versions, version.Version)
+	}
+	return versions, nil
+}
+
+// CheckAPIVersion fails with *UnsupportedAPIVersionError when the instance doesn't support APIVersion
+func (connection *SF_connection) CheckAPIVersion(ctx context.Context) error {
+	versions, listingError := connection.SupportedAPIVersions(ctx)
+	if listingError != nil {
+		return listingError
+	}
+	for _, version := range versions {
+		if version == connection.APIVersion() {
+			return nil
+		}
+	}
+	return &UnsupportedAPIVersionError{Requested: connection.APIVersion(), Available: versions}
+}
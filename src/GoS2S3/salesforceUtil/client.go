@@ -1,118 +1,160 @@
-// TODO: 
-//	- All the function call returning a boolean can be refactored to return also an error instead of exiting? 
-//	- It is probably a good idea to include a "contructor" method  on the SF_connection type 
-//		that accepts a map of fields and sets the corresponding fields so that we don't have to expose them
+// TODO:
+//   - It is probably a good idea to include a "contructor" method  on the SF_connection type
+//     that accepts a map of fields and sets the corresponding fields so that we don't have to expose them
 package salesforceUtil
 
 import (
-	"os"
-	"net/http"	
-	"io/ioutil"	
-	"log"	
-	"encoding/json"
 	"GoS2S3/SalesforceWSDL"
+	"GoS2S3/httpUtil"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"time"
 )
 
-func (connection *SF_connection) GetAuthenticationToken() string {
-	var authenticationRequest string
-	authenticationRequest += connection.TargetURI + "/services/oauth2/token?grant_type=password"
-	authenticationRequest += "&client_id=" + connection.ClientId
-	authenticationRequest += "&client_secret=" + connection.ClientSecret
-	authenticationRequest += "&username=" + connection.Username
-	authenticationRequest += "&password=" + connection.Password + connection.SecurityToken
-	
-	response, requestError := http.PostForm(authenticationRequest, nil)
-	if requestError != nil {
-		response.Body.Close()
-		log.Println("ERROR: Authentication failure!")
-		if connection.Debug {
-			log.Println(requestError)
-		} else {
-			log.Println("Error: an error occurred during the execution, please run with -debug for more information")
-		}
-		
-		os.Exit(1)
+// GetAuthenticationToken logs in with the configured OAuth grant and stores the token in AuthenticationToken.
+// A rejected login is reported as *AuthenticationError.
+func (connection *SF_connection) GetAuthenticationToken(ctx context.Context) (string, error) {
+	const operation = "authentication"
+
+	tokenURL, urlError := connection.tokenURL()
+	if urlError != nil {
+		return "", urlError
+	}
+
+	response, body, fetchError := connection.fetch(ctx, operation, tokenURL, func() (*http.Response, error) {
+		return connection.requestAccessToken(ctx)
+	})
+	if fetchError != nil {
+		return "", fetchError
 	}
-	defer response.Body.Close() 
-
-	log.Println("Authentication SUCCESSFUL")
-	
-	body, _ := ioutil.ReadAll(response.Body)
-	//fmt.Printf("%s\n", string(body))
-	//fmt.Println(string(body))
-	var jsonBody map[string] interface {}
-	if err := json.Unmarshal(body, &jsonBody); err != nil {
-        panic(err)
+	if response.StatusCode != http.StatusOK {
+		return "", errorFromResponse(operation, response, body)
 	}
-	
-	connection.AuthenticationToken.Access_token = jsonBody["access_token"].(string)
-	connection.AuthenticationToken.Instance_url = jsonBody["instance_url"].(string)
-	connection.AuthenticationToken.Id = jsonBody["id"].(string)
-	connection.AuthenticationToken.Token_type = jsonBody["token_type"].(string)
-	connection.AuthenticationToken.Issued_at = jsonBody["issued_at"].(string)
-	connection.AuthenticationToken.Signature = jsonBody["signature"].(string)
 
-	if connection.Debug {
-		log.Printf("Access token: %s", connection.AuthenticationToken.Access_token)
+	// not every grant returns all the fields (ie. the JWT bearer flow has no signature)
+	var token AccessToken
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", unexpectedResponse(operation, response.StatusCode, body, err)
+	}
+	if token.Access_token == "" {
+		return "", &MissingFieldError{Operation: operation, Field: "access_token"}
 	}
+	if token.Instance_url == "" {
+		return "", &MissingFieldError{Operation: operation, Field: "instance_url"}
+	}
+	connection.AuthenticationToken = token
 
+	log.Printf("Authentication SUCCESSFUL, following instance %s", token.Instance_url)
+	if connection.Debug {
+		log.Printf("Access token of type %s issued at %s", connection.AuthenticationToken.Token_type, connection.AuthenticationToken.Issued_at)
+	}
 
-	return connection.AuthenticationToken.Access_token
+	return connection.AuthenticationToken.Access_token, nil
 }
 
+// RequestPageOAuth downloads a Salesforce UI page using the session cookies
+func (connection *SF_connection) RequestPageOAuth(ctx context.Context, targetUrl string) (string, error) {
+	const operation = "page request"
 
-// TODO handle request error
-func (connection *SF_connection) RequestPageOAuth(targetUrl string) string {
-	client := &http.Client{}
-	request, requestError := http.NewRequest("GET", targetUrl, nil)
+	request, requestError := http.NewRequestWithContext(ctx, "GET", targetUrl, nil)
 	if requestError != nil {
-		return ""
+		return "", requestError
 	}
-	cookieOrg := http.Cookie{ Name: "oid", Value: connection.OrganizationId }
-	cookieSid := http.Cookie{ Name: "sid", Value: connection.SoapLogin.SessionId }
+	cookieOrg := http.Cookie{Name: "oid", Value: connection.OrganizationId}
+	cookieSid := http.Cookie{Name: "sid", Value: connection.SoapLogin.SessionId}
 
 	request.AddCookie(&cookieOrg)
 	request.AddCookie(&cookieSid)
 
-	response, responseError := client.Do(request)
-	if responseError != nil {
-		log.Println(responseError)
+	response, body, fetchError := connection.fetch(ctx, operation, targetUrl, func() (*http.Response, error) {
+		return connection.httpClient().Do(request)
+	})
+	if fetchError != nil {
+		return "", fetchError
 	}
-	defer response.Body.Close()
-
-	body, readError := ioutil.ReadAll(response.Body) 
-	if readError != nil {
-		if connection.Debug {
-			log.Println(readError)
-		} else {
-			log.Println("Error: an error occurred during the execution, please run with -debug for more information")
-		}
+	if response.StatusCode != http.StatusOK {
+		return "", unexpectedResponse(operation, response.StatusCode, body, nil)
+	}
+	// an expired session is answered with the login page, not with an error status
+	if LooksLikeLoginPage(response.Request.URL, body) {
+		return "", &SessionExpiredError{Operation: operation, Reason: "redirected to the login page"}
 	}
 
-	return string(body)
+	return string(body), nil
+}
+
+// fetch performs the request sent by send and reads its body, under the retry policy of the connection:
+// network failures and retryable statuses are attempted again, any other status is left to the caller
+func (connection *SF_connection) fetch(ctx context.Context, operation string, targetURL string, send func() (*http.Response, error)) (*http.Response, []byte, error) {
+	var response *http.Response
+	var body []byte
+	fetchError := connection.RetryPolicy.Do(ctx, operation, func() error {
+		var sendError error
+		response, sendError = send()
+		if sendError != nil {
+			return &NetworkError{Operation: operation, URL: targetURL, Err: sendError}
+		}
+		defer response.Body.Close()
+
+		var readError error
+		body, readError = ioutil.ReadAll(response.Body)
+		if readError != nil {
+			return &NetworkError{Operation: operation, URL: targetURL, Err: readError}
+		}
+		if connection.RetryPolicy.RetryableStatus(response.StatusCode) {
+			return unexpectedResponse(operation, response.StatusCode, body, nil)
+		}
+		return nil
+	})
+	return response, body, fetchError
+}
+
+// httpClient returns the injected HTTP client or the default one
+func (connection *SF_connection) httpClient() *http.Client {
+	if connection.HTTPClient != nil {
+		return connection.HTTPClient
+	}
+	return httpUtil.DefaultClient()
 }
 
-type SF_connection struct{
-	TargetURI string
-	Username string
-	Password string
-	SecurityToken string
-	ClientSecret string
-	ClientId string
+type SF_connection struct {
+	TargetURI           string
+	MyDomain            string
+	Environment         string
+	ApiVersion          string
+	Username            string
+	Password            string
+	SecurityToken       string
+	ClientSecret        string
+	ClientId            string
+	GrantType           string
+	PrivateKey          string
+	RefreshToken        string
 	AuthenticationToken AccessToken
-	SoapEndpoint string
-	SoapLogin SalesforceWSDL.LoginResult
-	SessionId string
-	OrganizationId string
-	ConnectionCookies map[string] interface {}	
-	Debug bool
+	SoapEndpoint        string
+	SoapLogin           SalesforceWSDL.LoginResult
+	SessionId           string
+	OrganizationId      string
+	ConnectionCookies   map[string]interface{}
+	Soap                *SalesforceWSDL.Soap // client of the current session, set by AuthenticateThroughSOAP
+	SessionStarted      time.Time
+	SessionMaxAge       time.Duration // the session is renewed beforehand once older, 0 waits for it to expire
+	TokenCacheFolder    string        // the session is kept between runs in this folder when set
+	TokenCacheKey       string        // 32 bytes key, in hexadecimal or base64, encrypting the token cache
+	HTTPClient          *http.Client  // client of every call, the shared default one when nil
+	RetryPolicy         httpUtil.RetryPolicy
+	Compression         bool // gzip the SOAP requests and responses
+	Debug               bool
 }
 
 type AccessToken struct {
 	Access_token string
 	Instance_url string
-	Id string
-	Token_type string
-	Issued_at string
-	Signature string
+	Id           string
+	Token_type   string
+	Issued_at    string
+	Signature    string
 }
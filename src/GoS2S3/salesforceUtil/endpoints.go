@@ -0,0 +1,112 @@
+package salesforceUtil
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// values accepted by SF_connection.Environment
+const (
+	EnvironmentProduction = "production"
+	EnvironmentSandbox    = "sandbox"
+)
+
+const (
+	productionLoginURL = "https://login.salesforce.com"
+	sandboxLoginURL    = "https://test.salesforce.com"
+	myDomainSuffix     = ".my.salesforce.com"
+)
+
+// LoginURL returns the base URL used to log in, in order of preference:
+// the explicit targetURI, the My Domain host (a bare name like "acme" becomes acme.my.salesforce.com)
+// or the generic login host of the environment (test.salesforce.com for sandboxes)
+func LoginURL(targetURI string, myDomain string, environment string) (string, error) {
+	switch {
+	case targetURI != "":
+		return normalizeBaseURL(targetURI)
+	case myDomain != "":
+		if !strings.Contains(myDomain, ".") && !strings.Contains(myDomain, "/") {
+			myDomain += myDomainSuffix
+		}
+		return normalizeBaseURL(myDomain)
+	case environment == EnvironmentSandbox:
+		return sandboxLoginURL, nil
+	case environment == "" || environment == EnvironmentProduction:
+		return productionLoginURL, nil
+	}
+	return "", fmt.Errorf("unknown environment %q", environment)
+}
+
+// IsGenericLoginHost tells if loginURL is login.salesforce.com or test.salesforce.com rather than a My Domain
+func IsGenericLoginHost(loginURL string) bool {
+	return loginURL == productionLoginURL || loginURL == sandboxLoginURL
+}
+
+// normalizeBaseURL accepts a host or an URL and returns it as https://host without trailing path
+func normalizeBaseURL(rawURL string) (string, error) {
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+	parsedURL, parseError := url.Parse(rawURL)
+	if parseError != nil {
+		return "", parseError
+	}
+	if parsedURL.Host == "" {
+		return "", fmt.Errorf("%q has no host", rawURL)
+	}
+	if parsedURL.Scheme != "https" {
+		return "", fmt.Errorf("%q must use https", rawURL)
+	}
+	return "https://" + strings.ToLower(parsedURL.Host), nil
+}
+
+// LoginURL returns the login host chosen by TargetURI, MyDomain and Environment
+func (connection *SF_connection) LoginURL() (string, error) {
+	return LoginURL(connection.TargetURI, connection.MyDomain, connection.Environment)
+}
+
+func (connection *SF_connection) tokenURL() (string, error) {
+	loginURL, loginError := connection.LoginURL()
+	if loginError != nil {
+		return "", loginError
+	}
+	return loginURL + "/services/oauth2/token", nil
+}
+
+// jwtAudience is the aud claim of the JWT bearer assertion: Salesforce expects the generic
+// login host of the environment even when the token is requested on a My Domain
+func (connection *SF_connection) jwtAudience() string {
+	loginURL, _ := connection.LoginURL()
+	if connection.Environment == EnvironmentSandbox || loginURL == sandboxLoginURL || strings.HasSuffix(loginURL, ".sandbox"+myDomainSuffix) {
+		return sandboxLoginURL
+	}
+	return productionLoginURL
+}
+
+// InstanceURL resolves path against the instance_url returned by the OAuth login, every call
+// following the login is sent to that host instead of the login one
+func (connection *SF_connection) InstanceURL(path string) (string, error) {
+	if connection.AuthenticationToken.Instance_url == "" {
+		return "", errors.New("not authenticated: instance_url is unknown")
+	}
+	instanceURL, parseError := url.Parse(connection.AuthenticationToken.Instance_url)
+	if parseError != nil {
+		return "", parseError
+	}
+	reference, parseError := url.Parse(path)
+	if parseError != nil {
+		return "", parseError
+	}
+	return instanceURL.ResolveReference(reference).String(), nil
+}
+
+// soapLoginURL is the enterprise SOAP endpoint of the login host, used by the SOAP Login call
+func (connection *SF_connection) soapLoginURL() (string, error) {
+	loginURL, loginError := connection.LoginURL()
+	if loginError != nil {
+		return "", loginError
+	}
+	return loginURL + "/services/Soap/c/" + connection.APIVersion(), nil
+}
@@ -0,0 +1,100 @@
+package salesforceUtil
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// maximum number of body bytes kept in an UnexpectedResponseError
+const maxErrorBodyLength = 512
+
+// AuthenticationError is returned when Salesforce rejects the credentials, Code and Description
+// come from the OAuth error response (ie. "invalid_grant", "authentication failure")
+// or from the SOAP login fault
+type AuthenticationError struct {
+	StatusCode  int
+	Code        string
+	Description string
+}
+
+func (authenticationError *AuthenticationError) Error() string {
+	return fmt.Sprintf("authentication rejected: %s: %s", authenticationError.Code, authenticationError.Description)
+}
+
+// NetworkError is returned when Salesforce could not be reached or the response could not be read
+type NetworkError struct {
+	Operation string
+	URL       string
+	Err       error
+}
+
+func (networkError *NetworkError) Error() string {
+	return fmt.Sprintf("%s: network failure calling %s: %v", networkError.Operation, networkError.URL, networkError.Err)
+}
+
+func (networkError *NetworkError) Unwrap() error {
+	return networkError.Err
+}
+
+// UnexpectedResponseError is returned when Salesforce answers with an error status
+// or with a body that can't be decoded
+type UnexpectedResponseError struct {
+	Operation  string
+	StatusCode int
+	Body       string
+	Err        error
+}
+
+func (responseError *UnexpectedResponseError) Error() string {
+	message := fmt.Sprintf("%s: unexpected response (HTTP %d)", responseError.Operation, responseError.StatusCode)
+	if responseError.Err != nil {
+		message += ": " + responseError.Err.Error()
+	}
+	if responseError.Body != "" {
+		message += ": " + responseError.Body
+	}
+	return message
+}
+
+func (responseError *UnexpectedResponseError) Unwrap() error {
+	return responseError.Err
+}
+
+// HTTPStatusCode lets the retry policy retry the throttling and server errors
+func (responseError *UnexpectedResponseError) HTTPStatusCode() int {
+	return responseError.StatusCode
+}
+
+// MissingFieldError is returned when a field needed to continue is missing from a response
+type MissingFieldError struct {
+	Operation string
+	Field     string
+}
+
+func (missingFieldError *MissingFieldError) Error() string {
+	return fmt.Sprintf("%s: field %q missing from the response", missingFieldError.Operation, missingFieldError.Field)
+}
+
+// oauthErrorResponse is the body returned by the OAuth endpoints on failure
+type oauthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// errorFromResponse turns a failed response into an AuthenticationError when the body is an
+// OAuth error, or into an UnexpectedResponseError otherwise
+func errorFromResponse(operation string, response *http.Response, body []byte) error {
+	var oauthError oauthErrorResponse
+	if json.Unmarshal(body, &oauthError) == nil && oauthError.Error != "" {
+		return &AuthenticationError{StatusCode: response.StatusCode, Code: oauthError.Error, Description: oauthError.ErrorDescription}
+	}
+	return unexpectedResponse(operation, response.StatusCode, body, nil)
+}
+
+func unexpectedResponse(operation string, statusCode int, body []byte, cause error) *UnexpectedResponseError {
+	if len(body) > maxErrorBodyLength {
+		body = body[:maxErrorBodyLength]
+	}
+	return &UnexpectedResponseError{Operation: operation, StatusCode: statusCode, Body: string(body), Err: cause}
+}
@@ -0,0 +1,83 @@
+package salesforceUtil
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const jwtBearerGrantType = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+
+// Salesforce accepts assertions expiring at most 3 minutes in the future
+const jwtAssertionLifetime = 3 * time.Minute
+
+// requestJWTBearerToken exchanges a locally signed assertion for an access token,
+// the connected app must have the certificate matching PrivateKey and the user pre-authorized
+func (connection *SF_connection) requestJWTBearerToken(ctx context.Context) (*http.Response, error) {
+	assertion, assertionError := connection.jwtAssertion(time.Now())
+	if assertionError != nil {
+		return nil, assertionError
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", jwtBearerGrantType)
+	form.Set("assertion", assertion)
+	return connection.requestToken(ctx, form)
+}
+
+// jwtAssertion builds the RS256 signed assertion for the JWT bearer flow:
+// iss is the connected app consumer key, sub the username and aud the login host
+func (connection *SF_connection) jwtAssertion(now time.Time) (string, error) {
+	privateKey, keyError := ParsePrivateKey(connection.PrivateKey)
+	if keyError != nil {
+		return "", keyError
+	}
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss": connection.ClientId,
+		"sub": connection.Username,
+		"aud": connection.jwtAudience(),
+		"exp": now.Add(jwtAssertionLifetime).Unix(),
+	})
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, signingError := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, digest[:])
+	if signingError != nil {
+		return "", signingError
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// ParsePrivateKey decodes a PEM encoded RSA private key in PKCS#1 or PKCS#8 form
+func ParsePrivateKey(pemKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return nil, errors.New("private key is not PEM encoded")
+	}
+
+	if privateKey, parseError := x509.ParsePKCS1PrivateKey(block.Bytes); parseError == nil {
+		return privateKey, nil
+	}
+	parsedKey, parseError := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if parseError != nil {
+		return nil, errors.New("private key is neither a PKCS#1 nor a PKCS#8 key")
+	}
+	privateKey, isRSA := parsedKey.(*rsa.PrivateKey)
+	if !isRSA {
+		return nil, errors.New("private key is not an RSA key")
+	}
+	return privateKey, nil
+}
@@ -0,0 +1,76 @@
+package salesforceUtil
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// OAuth grants supported by GetAuthenticationToken, password is used when GrantType is empty
+const (
+	GrantTypePassword          = "password"
+	GrantTypeJWTBearer         = "jwt_bearer"
+	GrantTypeRefreshToken      = "refresh_token"
+	GrantTypeClientCredentials = "client_credentials"
+)
+
+// requestAccessToken performs the token request of the configured grant
+func (connection *SF_connection) requestAccessToken(ctx context.Context) (*http.Response, error) {
+	switch connection.GrantType {
+	case GrantTypeJWTBearer:
+		return connection.requestJWTBearerToken(ctx)
+	case GrantTypeRefreshToken:
+		return connection.requestRefreshToken(ctx)
+	case GrantTypeClientCredentials:
+		return connection.requestClientCredentialsToken(ctx)
+	}
+	return connection.requestPasswordToken(ctx)
+}
+
+// requestToken posts the grant parameters to the token endpoint of the login host
+func (connection *SF_connection) requestToken(ctx context.Context, form url.Values) (*http.Response, error) {
+	tokenURL, urlError := connection.tokenURL()
+	if urlError != nil {
+		return nil, urlError
+	}
+	request, requestError := http.NewRequestWithContext(ctx, "POST", tokenURL, strings.NewReader(form.Encode()))
+	if requestError != nil {
+		return nil, requestError
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return connection.httpClient().Do(request)
+}
+
+func (connection *SF_connection) requestPasswordToken(ctx context.Context) (*http.Response, error) {
+	form := url.Values{}
+	form.Set("grant_type", "password")
+	form.Set("client_id", connection.ClientId)
+	form.Set("client_secret", connection.ClientSecret)
+	form.Set("username", connection.Username)
+	form.Set("password", connection.Password+connection.SecurityToken)
+	return connection.requestToken(ctx, form)
+}
+
+// requestRefreshToken obtains a new access token from a refresh token stored in the
+// configuration, the client secret is optional for connected apps not requiring it
+func (connection *SF_connection) requestRefreshToken(ctx context.Context) (*http.Response, error) {
+	form := url.Values{}
+	form.Set("grant_type", "refresh_token")
+	form.Set("client_id", connection.ClientId)
+	if connection.ClientSecret != "" {
+		form.Set("client_secret", connection.ClientSecret)
+	}
+	form.Set("refresh_token", connection.RefreshToken)
+	return connection.requestToken(ctx, form)
+}
+
+// requestClientCredentialsToken logs in as the integration user configured on the connected app,
+// Salesforce only accepts this grant on the My Domain host of the org
+func (connection *SF_connection) requestClientCredentialsToken(ctx context.Context) (*http.Response, error) {
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("client_id", connection.ClientId)
+	form.Set("client_secret", connection.ClientSecret)
+	return connection.requestToken(ctx, form)
+}
@@ -0,0 +1,123 @@
+package salesforceUtil
+
+import (
+	"GoS2S3/SalesforceWSDL"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SessionExpiredError is returned when Salesforce no longer accepts the session, ie. the
+// request was redirected to the login page or a SOAP call failed with INVALID_SESSION_ID
+type SessionExpiredError struct {
+	Operation string
+	Reason    string
+}
+
+func (sessionError *SessionExpiredError) Error() string {
+	return fmt.Sprintf("%s: session expired: %s", sessionError.Operation, sessionError.Reason)
+}
+
+// IsSessionExpired tells if err means the session has to be renewed
+func IsSessionExpired(err error) bool {
+	var sessionError *SessionExpiredError
+	if errors.As(err, &sessionError) {
+		return true
+	}
+	var fault *SalesforceWSDL.SOAPFault
+	return errors.As(err, &fault) && strings.Contains(fault.Code, "INVALID_SESSION_ID")
+}
+
+// LooksLikeLoginPage tells if a response for finalURL with the given body start is the login page
+// Salesforce serves, directly or through a redirect, instead of the requested resource when the session is invalid
+func LooksLikeLoginPage(finalURL *url.URL, bodyStart []byte) bool {
+	if finalURL != nil {
+		loginHost := "https://" + strings.ToLower(finalURL.Host)
+		if IsGenericLoginHost(loginHost) || finalURL.Query().Get("ec") != "" {
+			return true
+		}
+	}
+	// the UI answers an invalid session with a script sending the browser to /?ec=302&startURL=...
+	return bytes.Contains(bodyStart, []byte("?ec=30")) || bytes.Contains(bodyStart, []byte("INVALID_SESSION_ID"))
+}
+
+// SessionAge returns for how long the current session has been open
+func (connection *SF_connection) SessionAge() time.Duration {
+	if connection.SessionStarted.IsZero() {
+		return 0
+	}
+	return time.Since(connection.SessionStarted)
+}
+
+// Reauthenticate performs a new OAuth and SOAP login, replacing the session and the download cookies
+func (connection *SF_connection) Reauthenticate(ctx context.Context) error {
+	log.Printf("Renewing the Salesforce session (open since %s)", connection.SessionAge().Round(time.Second))
+	if _, authenticationError := connection.GetAuthenticationToken(ctx); authenticationError != nil {
+		return authenticationError
+	}
+	_, _, soapError := connection.AuthenticateThroughSOAP(ctx)
+	return soapError
+}
+
+// EnsureSession renews the session beforehand when it is older than SessionMaxAge. The previous
+// session is still valid and is logged out, unless it is kept in the token cache.
+func (connection *SF_connection) EnsureSession(ctx context.Context) error {
+	if connection.SessionMaxAge <= 0 || connection.SessionAge() <= connection.SessionMaxAge {
+		return nil
+	}
+
+	previousSoap, previousSessionId := connection.Soap, connection.SoapLogin.SessionId
+	if renewalError := connection.Reauthenticate(ctx); renewalError != nil {
+		return renewalError
+	}
+	// a new login of the same user can be given back the session still open, which must be kept
+	if previousSoap == nil || previousSessionId == "" || previousSessionId == connection.SoapLogin.SessionId || connection.TokenCacheEnabled() {
+		return nil
+	}
+	if _, logoutError := previousSoap.LogoutContext(ctx, &SalesforceWSDL.Logout{}); logoutError != nil {
+		log.Printf("Error logging out the previous Salesforce session: %v", logoutError)
+	} else {
+		log.Println("Logged out the previous Salesforce session")
+	}
+	return nil
+}
+
+// WithSession runs operation with a valid session: if it fails because the session expired,
+// the connection logs in again and operation is retried once
+func (connection *SF_connection) WithSession(ctx context.Context, operation func() error) error {
+	if sessionError := connection.EnsureSession(ctx); sessionError != nil {
+		return sessionError
+	}
+
+	operationError := operation()
+	if !IsSessionExpired(operationError) {
+		return operationError
+	}
+
+	log.Printf("Salesforce session expired: %v", operationError)
+	if sessionError := connection.Reauthenticate(ctx); sessionError != nil {
+		return sessionError
+	}
+	return operation()
+}
+
+// Logout closes the SOAP session, it does nothing if no session was opened
+// or when the session is kept in the token cache for the next run
+func (connection *SF_connection) Logout(ctx context.Context) error {
+	if connection.Soap == nil || connection.SoapLogin.SessionId == "" || connection.TokenCacheEnabled() {
+		return nil
+	}
+
+	if _, logoutError := connection.Soap.LogoutContext(ctx, &SalesforceWSDL.Logout{}); logoutError != nil {
+		return logoutError
+	}
+	log.Println("Logged out from Salesforce")
+	connection.SoapLogin.SessionId = ""
+	connection.SessionStarted = time.Time{}
+	return nil
+}
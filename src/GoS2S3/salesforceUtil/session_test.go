@@ -0,0 +1,110 @@
+package salesforceUtil
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSalesforce answers the OAuth, identity and SOAP login calls, giving sessions numbered
+// from 1 (or always the same one with reuseSession), and records the sessions logged out
+type fakeSalesforce struct {
+	*httptest.Server
+	reuseSession bool
+
+	lock      sync.Mutex
+	logins    int
+	loggedOut []string
+}
+
+var sessionHeaderPattern = regexp.MustCompile(`<sessionId>([^<]*)</sessionId>`)
+
+func newFakeSalesforce(t *testing.T, reuseSession bool) *fakeSalesforce {
+	fake := &fakeSalesforce{reuseSession: reuseSession}
+	fake.Server = httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := ioutil.ReadAll(request.Body)
+		fake.lock.Lock()
+		defer fake.lock.Unlock()
+		switch {
+		case request.URL.Path == "/services/oauth2/token":
+			fmt.Fprintf(writer, `{"access_token":"token","instance_url":%q,"id":%q,"token_type":"Bearer"}`, fake.URL, fake.URL+"/id/00D/005")
+		case strings.HasPrefix(request.URL.Path, "/id/"):
+			fmt.Fprintf(writer, `{"organization_id":"00D","urls":{"enterprise":%q}}`, fake.URL+"/services/Soap/c/{version}/00D")
+		case strings.Contains(string(body), "login>"):
+			fake.logins++
+			sessionId := fmt.Sprintf("session-%d", fake.logins)
+			if fake.reuseSession {
+				sessionId = "session-1"
+			}
+			fmt.Fprintf(writer, `<?xml version="1.0" encoding="UTF-8"?><soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/" xmlns="urn:enterprise.soap.sforce.com"><soapenv:Body><loginResponse><result><serverUrl>%s</serverUrl><sessionId>%s</sessionId></result></loginResponse></soapenv:Body></soapenv:Envelope>`, fake.URL+"/services/Soap/c/44.0/00D", sessionId)
+		case strings.Contains(string(body), "logout>"):
+			if match := sessionHeaderPattern.FindSubmatch(body); match != nil {
+				fake.loggedOut = append(fake.loggedOut, string(match[1]))
+			}
+			fmt.Fprint(writer, `<?xml version="1.0" encoding="UTF-8"?><soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/" xmlns="urn:enterprise.soap.sforce.com"><soapenv:Body><logoutResponse/></soapenv:Body></soapenv:Envelope>`)
+		default:
+			t.Errorf("unexpected request %s %s", request.Method, request.URL)
+			writer.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return fake
+}
+
+func TestEnsureSessionLogsOutTheRenewedSession(t *testing.T) {
+	tests := []struct {
+		name         string
+		reuseSession bool
+		tokenCache   bool
+		loggedOut    []string
+	}{
+		{name: "new session", loggedOut: []string{"session-1"}},
+		{name: "same session given back", reuseSession: true},
+		{name: "session kept in the token cache", tokenCache: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := newFakeSalesforce(t, test.reuseSession)
+			defer fake.Close()
+
+			connection := &SF_connection{
+				TargetURI:         fake.URL,
+				Username:          "backup@acme.com",
+				Password:          "password",
+				ClientId:          "client",
+				SessionMaxAge:     time.Hour,
+				HTTPClient:        fake.Client(),
+				ConnectionCookies: make(map[string]interface{}),
+			}
+			if test.tokenCache {
+				connection.TokenCacheFolder = t.TempDir()
+				connection.TokenCacheKey = "hUQ9Xw0wq7Qh5Lx2k3m1Z4cY8vB6nT0rS2dF7gJ9aEo="
+			}
+			if sessionError := connection.Reauthenticate(context.Background()); sessionError != nil {
+				t.Fatal(sessionError)
+			}
+
+			// still young, nothing happens
+			if sessionError := connection.EnsureSession(context.Background()); sessionError != nil || fake.logins != 1 {
+				t.Fatalf("EnsureSession() = %v after %d logins, want no renewal", sessionError, fake.logins)
+			}
+
+			connection.SessionStarted = time.Now().Add(-2 * time.Hour)
+			if sessionError := connection.EnsureSession(context.Background()); sessionError != nil {
+				t.Fatal(sessionError)
+			}
+			if fake.logins != 2 {
+				t.Errorf("%d logins, want the session renewed", fake.logins)
+			}
+			if strings.Join(fake.loggedOut, ",") != strings.Join(test.loggedOut, ",") {
+				t.Errorf("sessions logged out %v, want %v", fake.loggedOut, test.loggedOut)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package salesforceUtil
+
+import (
+	"GoS2S3/SalesforceWSDL"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// cachedSession is what the token cache keeps between runs, encrypted with TokenCacheKey
+type cachedSession struct {
+	AuthenticationToken AccessToken
+	SessionId           string
+	ServerUrl           string
+	SoapEndpoint        string
+	OrganizationId      string
+	SessionStarted      time.Time
+}
+
+// TokenCacheEnabled tells if the session is saved to and resumed from TokenCacheFolder
+func (connection *SF_connection) TokenCacheEnabled() bool {
+	return connection.TokenCacheFolder != ""
+}
+
+// tokenCacheFile is the cache file of this login: every user, login host and API version gets its own
+// file so that orgs backed up in parallel never write the same one
+func (connection *SF_connection) tokenCacheFile() (string, error) {
+	loginURL, loginError := connection.LoginURL()
+	if loginError != nil {
+		return "", loginError
+	}
+	identity := sha256.Sum256([]byte(loginURL + "\n" + connection.GrantType + "\n" + connection.ClientId + "\n" + connection.Username + "\n" + connection.APIVersion()))
+	return filepath.Join(connection.TokenCacheFolder, hex.EncodeToString(identity[:16])+".session"), nil
+}
+
+// ResumeCachedSession restores the session saved by a previous run and checks it with getUserInfo.
+// It returns false, after removing a stale cache file, when a fresh login is needed.
+func (connection *SF_connection) ResumeCachedSession(ctx context.Context) bool {
+	if !connection.TokenCacheEnabled() {
+		return false
+	}
+
+	cacheFile, fileError := connection.tokenCacheFile()
+	if fileError != nil {
+		log.Printf("Token cache disabled: %v", fileError)
+		return false
+	}
+	encryptedSession, readError := os.ReadFile(cacheFile)
+	if errors.Is(readError, os.ErrNotExist) {
+		return false
+	}
+	if readError != nil {
+		log.Printf("Error reading the token cache %s: %v", cacheFile, readError)
+		return false
+	}
+
+	var session cachedSession
+	plainSession, decryptError := decryptTokenCache(connection.TokenCacheKey, encryptedSession)
+	if decryptError == nil {
+		decryptError = json.Unmarshal(plainSession, &session)
+	}
+	if decryptError != nil {
+		log.Printf("Discarding the token cache %s: %v", cacheFile, decryptError)
+		os.Remove(cacheFile)
+		return false
+	}
+
+	soap := connection.newSoap(session.SoapEndpoint, &SalesforceWSDL.BasicAuth{})
+	soap.SetSession(&SalesforceWSDL.LoginResult{ServerUrl: session.SoapEndpoint, SessionId: session.SessionId})
+	userInfo, validationError := soap.GetUserInfoContext(ctx, &SalesforceWSDL.GetUserInfo{})
+	if validationError == nil && (userInfo.Result == nil || userInfo.Result.OrganizationId == nil) {
+		validationError = errors.New("getUserInfo returned no user")
+	}
+	var fault *SalesforceWSDL.SOAPFault
+	switch {
+	case validationError == nil:
+	case IsSessionExpired(validationError):
+		// only a session refused by Salesforce makes the cache stale
+		log.Println("Cached Salesforce session expired, logging in again")
+		os.Remove(cacheFile)
+		return false
+	case errors.As(validationError, &fault):
+		log.Printf("Cached Salesforce session refused, logging in again: %v", validationError)
+		os.Remove(cacheFile)
+		return false
+	default:
+		// network errors and unexpected responses say nothing about the session, the cache is kept
+		log.Printf("Cached Salesforce session could not be checked, logging in again: %v", validationError)
+		return false
+	}
+
+	connection.AuthenticationToken = session.AuthenticationToken
+	connection.SoapLogin.SessionId = session.SessionId
+	connection.SoapLogin.ServerUrl = session.ServerUrl
+	connection.SoapEndpoint = session.SoapEndpoint
+	connection.OrganizationId = session.OrganizationId
+	connection.Soap = soap
+	connection.SessionStarted = session.SessionStarted
+	connection.ConnectionCookies["oid"] = connection.OrganizationId
+	connection.ConnectionCookies["sid"] = connection.SoapLogin.SessionId
+
+	log.Printf("Reusing the cached Salesforce session (open since %s)", connection.SessionAge().Round(time.Second))
+	return true
+}
+
+// saveCachedSession writes the current session to the token cache, readable by the owner only
+func (connection *SF_connection) saveCachedSession() error {
+	cacheFile, fileError := connection.tokenCacheFile()
+	if fileError != nil {
+		return fileError
+	}
+
+	plainSession, _ := json.Marshal(cachedSession{
+		AuthenticationToken: connection.AuthenticationToken,
+		SessionId:           connection.SoapLogin.SessionId,
+		ServerUrl:           connection.SoapLogin.ServerUrl,
+		SoapEndpoint:        connection.SoapEndpoint,
+		OrganizationId:      connection.OrganizationId,
+		SessionStarted:      connection.SessionStarted,
+	})
+	encryptedSession, encryptError := encryptTokenCache(connection.TokenCacheKey, plainSession)
+	if encryptError != nil {
+		return encryptError
+	}
+
+	if creationError := os.MkdirAll(connection.TokenCacheFolder, 0700); creationError != nil {
+		return creationError
+	}
+	// written aside and renamed, a run killed halfway never leaves a truncated cache
+	temporaryFile := cacheFile + ".tmp"
+	if writeError := os.WriteFile(temporaryFile, encryptedSession, 0600); writeError != nil {
+		return writeError
+	}
+	return os.Rename(temporaryFile, cacheFile)
+}
+
+// ParseTokenCacheKey decodes TokenCacheKey, a random 32 bytes key given as 64 hexadecimal digits
+// or in base64 (ie. the output of "openssl rand -base64 32"). A passphrase is refused: the key is
+// used as is, without a derivation that would make guessing it slow.
+func ParseTokenCacheKey(key string) ([]byte, error) {
+	if key == "" {
+		return nil, errors.New("TokenCacheKey is empty")
+	}
+	if rawKey, decodeError := hex.DecodeString(key); decodeError == nil && len(rawKey) == tokenCacheKeySize {
+		return rawKey, nil
+	}
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if rawKey, decodeError := encoding.DecodeString(key); decodeError == nil && len(rawKey) == tokenCacheKeySize {
+			return rawKey, nil
+		}
+	}
+	return nil, fmt.Errorf("TokenCacheKey must be %d random bytes in hexadecimal or base64, ie. generated with \"openssl rand -base64 %d\"", tokenCacheKeySize, tokenCacheKeySize)
+}
+
+// size of the AES-256 key encrypting the token cache
+const tokenCacheKeySize = 32
+
+// tokenCacheCipher returns the AES-256-GCM cipher of the configured key
+func tokenCacheCipher(key string) (cipher.AEAD, error) {
+	rawKey, keyError := ParseTokenCacheKey(key)
+	if keyError != nil {
+		return nil, keyError
+	}
+	block, blockError := aes.NewCipher(rawKey)
+	if blockError != nil {
+		return nil, blockError
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptTokenCache returns the random nonce followed by the sealed plaintext
+func encryptTokenCache(key string, plaintext []byte) ([]byte, error) {
+	gcm, cipherError := tokenCacheCipher(key)
+	if cipherError != nil {
+		return nil, cipherError
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, randomError := io.ReadFull(rand.Reader, nonce); randomError != nil {
+		return nil, randomError
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func decryptTokenCache(key string, ciphertext []byte) ([]byte, error) {
+	gcm, cipherError := tokenCacheCipher(key)
+	if cipherError != nil {
+		return nil, cipherError
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, errors.New("token cache is truncated")
+	}
+	plaintext, openError := gcm.Open(nil, ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():], nil)
+	if openError != nil {
+		return nil, fmt.Errorf("token cache can't be decrypted, was TokenCacheKey changed? %w", openError)
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,98 @@
+package salesforceUtil
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+const userInfoEnvelope = `<?xml version="1.0" encoding="UTF-8"?>
+<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/" xmlns="urn:enterprise.soap.sforce.com">
+<soapenv:Body><getUserInfoResponse><result><organizationId>00D5g000004ABCDEAA</organizationId><userName>backup@acme.com</userName></result></getUserInfoResponse></soapenv:Body>
+</soapenv:Envelope>`
+
+const invalidSessionEnvelope = `<?xml version="1.0" encoding="UTF-8"?>
+<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/" xmlns:sf="urn:fault.enterprise.soap.sforce.com">
+<soapenv:Body><soapenv:Fault><faultcode>sf:INVALID_SESSION_ID</faultcode><faultstring>INVALID_SESSION_ID: Invalid Session ID found in SessionHeader: Illegal Session</faultstring></soapenv:Fault></soapenv:Body>
+</soapenv:Envelope>`
+
+func TestResumeCachedSession(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		resumed    bool
+		cacheKept  bool
+	}{
+		{name: "valid session", statusCode: http.StatusOK, body: userInfoEnvelope, resumed: true, cacheKept: true},
+		{name: "expired session", statusCode: http.StatusInternalServerError, body: invalidSessionEnvelope, resumed: false, cacheKept: false},
+		{name: "maintenance page", statusCode: http.StatusServiceUnavailable, body: "<html><body>Down for maintenance</body></html>", resumed: false, cacheKept: true},
+		{name: "unexpected response", statusCode: http.StatusOK, body: "not a SOAP envelope", resumed: false, cacheKept: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				writer.Header().Set("Content-Type", "text/xml; charset=utf-8")
+				writer.WriteHeader(test.statusCode)
+				writer.Write([]byte(test.body))
+			}))
+			defer server.Close()
+
+			saved := testConnection(t.TempDir())
+			saved.SoapEndpoint = server.URL
+			saved.SoapLogin.SessionId = "00D5g000004ABCD!session"
+			if saveError := saved.saveCachedSession(); saveError != nil {
+				t.Fatal(saveError)
+			}
+			cacheFile, _ := saved.tokenCacheFile()
+
+			resumed := testConnection(saved.TokenCacheFolder)
+			if ok := resumed.ResumeCachedSession(context.Background()); ok != test.resumed {
+				t.Errorf("ResumeCachedSession() = %v, want %v", ok, test.resumed)
+			}
+			if test.resumed && resumed.SoapLogin.SessionId != saved.SoapLogin.SessionId {
+				t.Errorf("SessionId = %q, want the cached one", resumed.SoapLogin.SessionId)
+			}
+			if _, statError := os.Stat(cacheFile); (statError == nil) != test.cacheKept {
+				t.Errorf("cache file kept = %v, want %v", statError == nil, test.cacheKept)
+			}
+		})
+	}
+}
+
+func testConnection(cacheFolder string) *SF_connection {
+	return &SF_connection{
+		TargetURI:         "https://login.salesforce.com",
+		Username:          "backup@acme.com",
+		ClientId:          "client",
+		TokenCacheFolder:  cacheFolder,
+		TokenCacheKey:     "hUQ9Xw0wq7Qh5Lx2k3m1Z4cY8vB6nT0rS2dF7gJ9aEo=",
+		ConnectionCookies: make(map[string]interface{}),
+	}
+}
+
+func TestParseTokenCacheKey(t *testing.T) {
+	tests := []struct {
+		key   string
+		valid bool
+	}{
+		{"hUQ9Xw0wq7Qh5Lx2k3m1Z4cY8vB6nT0rS2dF7gJ9aEo=", true},
+		{"hUQ9Xw0wq7Qh5Lx2k3m1Z4cY8vB6nT0rS2dF7gJ9aEo", true},
+		{"85443d5f0d30abb421e4bc76937635e1c618f2f07a9d3d2b4b6765ec6099f684", true},
+		{"", false},
+		{"correct horse battery staple", false},
+		{"hUQ9Xw0wq7Qh5Lx2k3m1Z4cY8vB6nT0r", false},
+		{"85443d5f0d30abb421e4bc76937635e1", false},
+	}
+	for _, test := range tests {
+		rawKey, keyError := ParseTokenCacheKey(test.key)
+		if (keyError == nil) != test.valid {
+			t.Errorf("ParseTokenCacheKey(%q) error = %v, want valid=%v", test.key, keyError, test.valid)
+		}
+		if keyError == nil && len(rawKey) != tokenCacheKeySize {
+			t.Errorf("ParseTokenCacheKey(%q) returned %d bytes", test.key, len(rawKey))
+		}
+	}
+}